@@ -0,0 +1,136 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// BandRenderer draws a frame too big to hold in memory by rendering it one
+// horizontal band at a time into a small strip buffer, blitting each band to
+// Target as it is finished. A 320x240 RGB565 frame needs 150KB; rendered as
+// 16 line bands it needs 10KB.
+//
+// The scene is drawn by a callback, which is called once per band. It is handed
+// a Band, a Drawer which looks like the whole frame but keeps only what falls
+// within the current band. Since Band implements Filler and Blitter, the same
+// drawing code works unchanged on a full framebuffer.
+type BandRenderer struct {
+	// Target is where finished bands are sent.
+	Target Blitter
+	// Rect is the area of Target to render.
+	Rect image.Rectangle
+	// Strip holds one band. Its height is the band height, and it must be at
+	// least as wide as Rect.
+	Strip Drawer
+	// Background, if not nil, is filled into the strip before each band is
+	// drawn. Otherwise the scene must draw every pixel itself.
+	Background color.Color
+}
+
+// NewBandRenderer returns a BandRenderer covering bounds of target, using an
+// RGB565 strip of bandHeight lines.
+func NewBandRenderer(target Blitter, bounds image.Rectangle, bandHeight int) *BandRenderer {
+	return &BandRenderer{
+		Target: target,
+		Rect:   bounds,
+		Strip:  NewRGB565(image.Rect(0, 0, bounds.Dx(), bandHeight)),
+	}
+}
+
+// Render draws the scene band by band and blits each band to Target. If Target
+// is a DoubleBufferer, it is flushed once all bands are sent.
+func (br *BandRenderer) Render(scene func(dst Drawer)) {
+	strip := br.Strip.Bounds()
+	height := strip.Dy()
+	if height <= 0 || strip.Dx() < br.Rect.Dx() {
+		panic("gfx: band strip too small")
+	}
+
+	for y := br.Rect.Min.Y; y < br.Rect.Max.Y; y += height {
+		band := &Band{
+			Strip:  br.Strip,
+			Rect:   br.Rect,
+			Clip:   image.Rect(br.Rect.Min.X, y, br.Rect.Max.X, min(y+height, br.Rect.Max.Y)),
+			offset: strip.Min.Sub(image.Pt(br.Rect.Min.X, y)),
+		}
+		if br.Background != nil {
+			Fill(br.Strip, strip, br.Background)
+		}
+		scene(band)
+
+		r := band.Clip.Add(band.offset)
+		if sub, ok := subImage(br.Strip, r); ok {
+			br.Target.Blit(sub, band.Clip.Min)
+		} else {
+			br.Target.Blit(window{br.Strip, r}, band.Clip.Min)
+		}
+	}
+
+	if db, ok := br.Target.(DoubleBufferer); ok {
+		db.Flush()
+	}
+}
+
+// Band is the Drawer a BandRenderer hands its scene. Its bounds are the whole
+// frame, but only what is drawn within Clip is kept; everything else is
+// dropped. At outside Clip returns transparent black.
+//
+// Scenes with a lot to draw can skip anything not overlapping Clip.
+type Band struct {
+	// Strip is the buffer the band is drawn into.
+	Strip Drawer
+	// Rect is the whole frame.
+	Rect image.Rectangle
+	// Clip is the part of Rect this band covers.
+	Clip image.Rectangle
+
+	// offset translates frame coordinates to Strip coordinates.
+	offset image.Point
+}
+
+func (b *Band) Bounds() image.Rectangle {
+	return b.Rect
+}
+
+func (b *Band) ColorModel() color.Model {
+	return b.Strip.ColorModel()
+}
+
+func (b *Band) At(x, y int) color.Color {
+	if !image.Pt(x, y).In(b.Clip) {
+		return color.Transparent
+	}
+	return b.Strip.At(x+b.offset.X, y+b.offset.Y)
+}
+
+func (b *Band) Set(x, y int, c color.Color) {
+	if !image.Pt(x, y).In(b.Clip) {
+		return
+	}
+	b.Strip.Set(x+b.offset.X, y+b.offset.Y, c)
+}
+
+// Fill implements gfx.Filler.
+func (b *Band) Fill(r image.Rectangle, c color.Color) {
+	r = r.Intersect(b.Clip)
+	if r.Empty() {
+		return
+	}
+	Fill(b.Strip, r.Add(b.offset), c)
+}
+
+// Blit implements gfx.Blitter. Only the part of src landing within Clip is
+// copied, using the strip's own Blit where it has one.
+func (b *Band) Blit(src image.Image, at image.Point) {
+	destRect, sp := clipBlit(b.Clip, src.Bounds(), at)
+	if destRect.Empty() {
+		return
+	}
+	srcRect := image.Rectangle{sp, sp.Add(destRect.Size())}
+	if sub, ok := src.(subImager); ok {
+		src = sub.SubImage(srcRect)
+	} else {
+		src = window{src, srcRect}
+	}
+	Blit(b.Strip, src, destRect.Min.Add(b.offset))
+}
@@ -0,0 +1,49 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Blitter = &Band{}
+	_ Filler  = &Band{}
+)
+
+// Test_BandRenderer checks a scene rendered in bands matches the same scene
+// drawn straight into a full framebuffer.
+func Test_BandRenderer(t *testing.T) {
+	bounds := image.Rect(0, 0, 50, 37)
+	sprite := NewRGB565(image.Rect(100, 100, 120, 120))
+	sprite.Fill(sprite.Rect, color.RGBA{0, 0, 0xFF, 0xFF})
+	sprite.Fill(image.Rect(105, 105, 110, 110), color.White)
+
+	scene := func(dst Drawer) {
+		Fill(dst, dst.Bounds(), color.RGBA{0x20, 0x40, 0x60, 0xFF})
+		Fill(dst, image.Rect(-5, 10, 30, 21), color.RGBA{0xFF, 0, 0, 0xFF})
+		Blit(dst, sprite, image.Pt(12, 5))
+		Blit(dst, sprite, image.Pt(40, 30))
+		for i := 0; i < 37; i++ {
+			dst.Set(i, i, color.White)
+		}
+	}
+
+	want := NewRGB565(bounds)
+	scene(want)
+
+	for _, height := range []int{1, 7, 16, 37, 50} {
+		got := &recordingBlitter{RGB565: NewRGB565(bounds)}
+		NewBandRenderer(got, bounds, height).Render(scene)
+
+		if len(got.blits) != (bounds.Dy()+height-1)/height {
+			t.Errorf("band height %d: %d blits", height, len(got.blits))
+		}
+		forAllPix(bounds, func(x, y int) {
+			if got.At(x, y) != want.At(x, y) {
+				t.Fatalf("band height %d: pixel (%d,%d) differs", height, x, y)
+			}
+		})
+	}
+}
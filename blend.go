@@ -0,0 +1,145 @@
+package gfx
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+)
+
+// AlphaMask is a mask which reports its coverage directly rather than through
+// color.Color. *image.Alpha is one. BlendMask is faster with an AlphaMask.
+type AlphaMask interface {
+	image.Image
+	AlphaAt(x, y int) color.Alpha
+}
+
+// Blend draws c over the pixel at x, y of dst with the given coverage: at 0xFF
+// the pixel becomes c (as far as c is opaque), at 0 it is left alone, and in
+// between the two are mixed. Pixels outside dst's bounds are ignored.
+//
+// It is the compositing step of BlendMask, for drawing that works a pixel at a
+// time, such as anti-aliased lines. *RGBA, *image.RGBA and *RGB565 are blended
+// directly in their pixel buffers; anything else goes through At and Set.
+func Blend(dst Drawer, x, y int, c color.Color, coverage uint8) {
+	if coverage == 0 || !image.Pt(x, y).In(dst.Bounds()) {
+		return
+	}
+	newBlender(c).pixel(dst, x, y, uint32(coverage))
+}
+
+// BlendMask draws c over dst through mask, such that mask.Bounds().Min lands on
+// at. Each pixel is blended as by Blend, with the mask's alpha as its coverage.
+func BlendMask(dst Drawer, at image.Point, mask image.Image, c color.Color) {
+	destRect, mp := clipBlit(dst.Bounds(), mask.Bounds(), at)
+	if destRect.Empty() {
+		return
+	}
+
+	coverage := func(x, y int) uint32 {
+		_, _, _, a := mask.At(x, y).RGBA()
+		return a >> 8
+	}
+	if am, ok := mask.(AlphaMask); ok {
+		coverage = func(x, y int) uint32 {
+			return uint32(am.AlphaAt(x, y).A)
+		}
+	}
+
+	b := newBlender(c)
+	var rgba *image.RGBA
+	switch d := dst.(type) {
+	case *RGBA:
+		d.dirtyAdd(destRect)
+		rgba = d.RGBA
+	case *image.RGBA:
+		rgba = d
+	case *RGB565:
+		d.dirtyAdd(destRect)
+		for y := 0; y < destRect.Dy(); y++ {
+			i := d.PixOffset(destRect.Min.X, destRect.Min.Y+y)
+			for x := 0; x < destRect.Dx(); x, i = x+1, i+rgb565Width {
+				if m := coverage(mp.X+x, mp.Y+y); m != 0 {
+					b.rgb565(d, i, m)
+				}
+			}
+		}
+		return
+	}
+
+	if rgba != nil {
+		for y := 0; y < destRect.Dy(); y++ {
+			i := rgba.PixOffset(destRect.Min.X, destRect.Min.Y+y)
+			for x := 0; x < destRect.Dx(); x, i = x+1, i+rgbaWidth {
+				if m := coverage(mp.X+x, mp.Y+y); m != 0 {
+					b.rgba(rgba, i, m)
+				}
+			}
+		}
+		return
+	}
+
+	for y := 0; y < destRect.Dy(); y++ {
+		for x := 0; x < destRect.Dx(); x++ {
+			if m := coverage(mp.X+x, mp.Y+y); m != 0 {
+				b.generic(dst, destRect.Min.X+x, destRect.Min.Y+y, m)
+			}
+		}
+	}
+}
+
+// blender composites one color over pixels, with 8 bit coverage.
+type blender struct {
+	r, g, b, a uint32
+}
+
+func newBlender(c color.Color) blender {
+	r, g, b, a := c.RGBA()
+	return blender{r, g, b, a}
+}
+
+// over returns the 16 bit channels of the color, scaled by coverage m, over d.
+func (b blender) over(m, dr, dg, db, da uint32) (r, g, bl, a uint32) {
+	sa := b.a * m / 0xFF
+	keep := 0xFFFF - sa
+	return b.r*m/0xFF + dr*keep/0xFFFF,
+		b.g*m/0xFF + dg*keep/0xFFFF,
+		b.b*m/0xFF + db*keep/0xFFFF,
+		sa + da*keep/0xFFFF
+}
+
+// pixel blends the pixel at x, y, which must be within dst's bounds.
+func (b blender) pixel(dst Drawer, x, y int, m uint32) {
+	switch d := dst.(type) {
+	case *RGBA:
+		d.dirtyAdd(image.Rect(x, y, x+1, y+1))
+		b.rgba(d.RGBA, d.PixOffset(x, y), m)
+	case *image.RGBA:
+		b.rgba(d, d.PixOffset(x, y), m)
+	case *RGB565:
+		d.dirtyAdd(image.Rect(x, y, x+1, y+1))
+		b.rgb565(d, d.PixOffset(x, y), m)
+	default:
+		b.generic(dst, x, y, m)
+	}
+}
+
+// rgba blends the pixel at offset i of p.
+func (b blender) rgba(p *image.RGBA, i int, m uint32) {
+	pix := p.Pix[i : i+rgbaWidth : i+rgbaWidth]
+	r, g, bl, a := b.over(m, uint32(pix[0])*0x101, uint32(pix[1])*0x101, uint32(pix[2])*0x101, uint32(pix[3])*0x101)
+	pix[0], pix[1], pix[2], pix[3] = uint8(r>>8), uint8(g>>8), uint8(bl>>8), uint8(a>>8)
+}
+
+// rgb565 blends the pixel at offset i of p, which is always opaque.
+func (b blender) rgb565(p *RGB565, i int, m uint32) {
+	dr, dg, db, _ := RGB565BE(binary.NativeEndian.Uint16(p.Pix[i : i+rgb565Width : i+rgb565Width])).RGBA()
+	r, g, bl, _ := b.over(m, dr, dg, db, 0xFFFF)
+	p.setRGB565BE(i, NewRGB565BE(uint8(r>>8), uint8(g>>8), uint8(bl>>8)))
+}
+
+// generic blends the pixel at x, y of dst through At and Set.
+func (b blender) generic(dst Drawer, x, y int, m uint32) {
+	dr, dg, db, da := dst.At(x, y).RGBA()
+	r, g, bl, a := b.over(m, dr, dg, db, da)
+	dst.Set(x, y, color.RGBA64{uint16(r), uint16(g), uint16(bl), uint16(a)})
+}
@@ -0,0 +1,83 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// Test_BlendMask checks the fast paths of BlendMask against the generic one.
+func Test_BlendMask(t *testing.T) {
+	rand.Seed(18)
+	bounds := image.Rect(0, 0, 16, 16)
+	targets := map[string]func() Drawer{
+		"RGBA":       func() Drawer { return NewRGBA(image.NewRGBA(bounds)) },
+		"image.RGBA": func() Drawer { return image.NewRGBA(bounds) },
+		"RGB565":     func() Drawer { return NewRGB565(bounds) },
+	}
+
+	for name, newTarget := range targets {
+		for i := 0; i < 20; i++ {
+			fast, slow := newTarget(), newTarget()
+			background := color.RGBA{uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256)), 0xFF}
+			Fill(fast, bounds, background)
+			Fill(slow, bounds, background)
+
+			mask := image.NewAlpha(randomRect(image.Rect(-4, -4, 20, 20)))
+			rand.Read(mask.Pix)
+			at := image.Pt(rand.Intn(24)-8, rand.Intn(24)-8)
+			c := color.NRGBA{uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256))}
+
+			BlendMask(fast, at, mask, c)
+			// hiding AlphaAt and the framebuffer's type forces the slowest path
+			BlendMask(plainDrawer{slow}, at, plainImage{mask}, c)
+
+			forAllPix(bounds, func(x, y int) {
+				if fast.At(x, y) != slow.At(x, y) {
+					t.Fatalf("%s: pixel (%d,%d) is %v, want %v", name, x, y, fast.At(x, y), slow.At(x, y))
+				}
+			})
+		}
+	}
+
+	// opaque coverage of an opaque color replaces the pixel outright
+	dst := NewRGB565(bounds)
+	mask := image.NewAlpha(image.Rect(0, 0, 1, 1))
+	mask.Pix[0] = 0xFF
+	BlendMask(dst, image.Pt(3, 3), mask, color.White)
+	if dst.At(3, 3) != RGB565BEModel.Convert(color.White) || dst.At(4, 3) == RGB565BEModel.Convert(color.White) {
+		t.Error("opaque blend did not replace exactly one pixel")
+	}
+}
+
+// plainImage hides everything but image.Image.
+type plainImage struct {
+	image.Image
+}
+
+func Test_Blend(t *testing.T) {
+	rand.Seed(21)
+	bounds := image.Rect(0, 0, 8, 8)
+	for _, fast := range []Drawer{NewRGBA(image.NewRGBA(bounds)), image.NewRGBA(bounds), NewRGB565(bounds)} {
+		slow := plainDrawer{image.NewRGBA(bounds)}
+		Fill(fast, bounds, color.Gray{0x40})
+		Fill(slow, bounds, fast.ColorModel().Convert(color.Gray{0x40}))
+		for i := 0; i < 200; i++ {
+			x, y := rand.Intn(10)-1, rand.Intn(10)-1
+			c := color.NRGBA{uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256))}
+			coverage := uint8(rand.Intn(256))
+			Blend(fast, x, y, c, coverage)
+			Blend(slow, x, y, c, coverage)
+			// the slow copy is kept in the fast one's color model
+			if image.Pt(x, y).In(bounds) {
+				slow.Set(x, y, fast.ColorModel().Convert(slow.At(x, y)))
+			}
+		}
+		forAllPix(bounds, func(x, y int) {
+			if fast.At(x, y) != fast.ColorModel().Convert(slow.At(x, y)) {
+				t.Fatalf("%T: pixel (%d,%d) is %v, want %v", fast, x, y, fast.At(x, y), slow.At(x, y))
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// The functions here let drawing code target any Drawer without caring what it
+// is. Each one uses the fastest method dst has: the gfx interface for the job if
+// dst implements it, then image/draw (which has fast paths for the standard
+// library's image types) if dst can hand out a SubImage, then plain At and Set.
+
+// subImager is implemented by the standard library's image types.
+type subImager interface {
+	SubImage(image.Rectangle) image.Image
+}
+
+// Blit copies src into dst, such that src.Bounds().Min lands on at.
+func Blit(dst Drawer, src image.Image, at image.Point) {
+	if b, ok := dst.(Blitter); ok {
+		b.Blit(src, at)
+		return
+	}
+
+	destRect, sp := clipBlit(dst.Bounds(), src.Bounds(), at)
+	if destRect.Empty() {
+		return
+	}
+
+	if sub, ok := subImage(dst, destRect); ok {
+		draw.Draw(sub, destRect, src, sp, draw.Src)
+		return
+	}
+
+	// only read the part of src that lands in dst
+	offset := sp.Sub(destRect.Min)
+	forAllPix(destRect, func(x, y int) {
+		dst.Set(x, y, src.At(x+offset.X, y+offset.Y))
+	})
+}
+
+// Fill sets every pixel of dst within r to c.
+func Fill(dst Drawer, r image.Rectangle, c color.Color) {
+	if f, ok := dst.(Filler); ok {
+		f.Fill(r, c)
+		return
+	}
+
+	r = r.Intersect(dst.Bounds())
+	if r.Empty() {
+		return
+	}
+
+	if sub, ok := subImage(dst, r); ok {
+		draw.Draw(sub, r, image.NewUniform(c), image.Point{}, draw.Src)
+		return
+	}
+
+	fill(dst, r, c)
+}
+
+// Scroll scrolls region of dst by amount pixels; positive amounts move the image
+// up. If region covers all of dst, a Scroller is preferred over a RegionScroller.
+// What ends up in the vacated area depends on which method is used: it may be
+// left as is, or (with a VectorScroller) hold what scrolled off the other edge.
+func Scroll(dst Drawer, region image.Rectangle, amount int) {
+	region = region.Intersect(dst.Bounds())
+	if region.Empty() || amount == 0 {
+		return
+	}
+
+	if s, ok := dst.(Scroller); ok && region.Eq(dst.Bounds()) {
+		s.Scroll(amount)
+		return
+	}
+
+	switch s := dst.(type) {
+	case RegionScroller:
+		s.RegionScroll(region, amount)
+		return
+	case VectorScroller:
+		s.VectorScroll(region, image.Pt(0, amount))
+		return
+	}
+
+	if amount >= region.Dy() || -amount >= region.Dy() {
+		return
+	}
+
+	// the rows that are still within region once moved
+	from := region.Add(image.Pt(0, amount)).Intersect(region)
+	if sub, ok := subImage(dst, region); ok {
+		// draw.Draw copies backwards if need be when src and dst are the same
+		draw.Draw(sub, from.Sub(image.Pt(0, amount)), sub, from.Min, draw.Src)
+		return
+	}
+
+	scroll(dst, region, amount)
+}
+
+// subImage returns the part of dst within r, if dst supports it.
+func subImage(dst Drawer, r image.Rectangle) (draw.Image, bool) {
+	s, ok := dst.(subImager)
+	if !ok {
+		return nil, false
+	}
+	sub, ok := s.SubImage(r).(draw.Image)
+	return sub, ok
+}
+
+// software implementation of scrolling a region. The vacated area is left as is.
+func scroll(dst Drawer, region image.Rectangle, amount int) {
+	if amount > 0 {
+		for y := region.Min.Y; y < region.Max.Y-amount; y++ {
+			for x := region.Min.X; x < region.Max.X; x++ {
+				dst.Set(x, y, dst.At(x, y+amount))
+			}
+		}
+		return
+	}
+
+	for y := region.Max.Y - 1; y >= region.Min.Y-amount; y-- {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			dst.Set(x, y, dst.At(x, y+amount))
+		}
+	}
+}
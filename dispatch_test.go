@@ -0,0 +1,62 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// plainDrawer hides everything but draw.Image, forcing the software paths.
+type plainDrawer struct {
+	draw.Image
+}
+
+func Test_Dispatch(t *testing.T) {
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	targets := map[string]func() Drawer{
+		"plain":       func() Drawer { return plainDrawer{image.NewRGBA(image.Rect(0, 0, 8, 8))} },
+		"image.RGBA":  func() Drawer { return image.NewRGBA(image.Rect(0, 0, 8, 8)) },
+		"image.NRGBA": func() Drawer { return image.NewNRGBA(image.Rect(0, 0, 8, 8)) },
+		"gfx.RGB565":  func() Drawer { return NewRGB565(image.Rect(0, 0, 8, 8)) },
+		"gfx.RGBA":    func() Drawer { return NewRGBA(image.NewRGBA(image.Rect(0, 0, 8, 8))) },
+	}
+
+	for name, newTarget := range targets {
+		dst := newTarget()
+		want := dst.ColorModel().Convert(red)
+
+		Fill(dst, image.Rect(-2, 6, 20, 20), red)
+		for x := 0; x < 8; x++ {
+			if dst.At(x, 6) != want || dst.At(x, 7) != want || dst.At(x, 5) == want {
+				t.Fatalf("%s: Fill missed (%d,6)", name, x)
+			}
+		}
+
+		src := image.NewRGBA(image.Rect(10, 10, 12, 12))
+		draw.Draw(src, src.Rect, image.NewUniform(red), image.Point{}, draw.Src)
+		Blit(dst, src, image.Pt(1, 1))
+		if dst.At(1, 1) != want || dst.At(2, 2) != want || dst.At(3, 3) == want {
+			t.Fatalf("%s: Blit went to the wrong place", name)
+		}
+
+		// move the blitted square and the filled rows up by one
+		Scroll(dst, image.Rect(0, 0, 8, 8), 1)
+		if dst.At(1, 0) != want || dst.At(2, 1) != want || dst.At(2, 2) == want || dst.At(0, 5) != want {
+			t.Fatalf("%s: Scroll did not move pixels up", name)
+		}
+
+		// and a region back down by two
+		Scroll(dst, image.Rect(0, 0, 4, 4), -2)
+		if dst.At(1, 2) != want || dst.At(2, 3) != want || dst.At(0, 5) != want {
+			t.Fatalf("%s: region Scroll did not move pixels down", name)
+		}
+	}
+
+	// huge sources are only read where they land
+	dst := plainDrawer{image.NewRGBA(image.Rect(0, 0, 8, 8))}
+	Blit(dst, image.NewUniform(red), image.Pt(-1e8, 4))
+	if dst.At(7, 4) != red || dst.At(0, 3) == red {
+		t.Error("plain: Blit of a Uniform missed")
+	}
+}
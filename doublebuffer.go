@@ -0,0 +1,125 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// DoubleBuffer gives DoubleBufferer semantics to any pair of back buffer and
+// front target. Drawing goes to the back buffer, which remembers what changed;
+// Flush then blits just the changed rectangles to the front. The front can be
+// anything implementing Blitter: a display driver, a file sink or another
+// framebuffer.
+//
+// Only changes made through the DoubleBuffer are tracked. Drawing to the back
+// buffer directly needs a call to Invalidate to be flushed.
+type DoubleBuffer struct {
+	// Drawer is the back buffer.
+	Drawer
+	// Front is where Flush sends changes.
+	Front Blitter
+	// ScrollFill is applied after scrolling, on top of whatever the back buffer does.
+	ScrollFill ScrollFill
+
+	dirty Region
+}
+
+// NewDoubleBuffer returns a DoubleBuffer drawing into back and flushing to front.
+// Nothing is considered dirty to begin with; use Invalidate if back and front
+// do not start out the same.
+func NewDoubleBuffer(back Drawer, front Blitter) *DoubleBuffer {
+	return &DoubleBuffer{
+		Drawer: back,
+		Front:  front,
+	}
+}
+
+func (db *DoubleBuffer) Set(x, y int, c color.Color) {
+	db.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	db.Drawer.Set(x, y, c)
+}
+
+// Fill implements gfx.Filler.
+func (db *DoubleBuffer) Fill(r image.Rectangle, c color.Color) {
+	db.dirtyAdd(r)
+	Fill(db.Drawer, r, c)
+}
+
+// Blit implements gfx.Blitter.
+func (db *DoubleBuffer) Blit(src image.Image, at image.Point) {
+	db.dirtyAdd(src.Bounds().Sub(src.Bounds().Min).Add(at))
+	Blit(db.Drawer, src, at)
+}
+
+// Scroll implements gfx.Scroller.
+func (db *DoubleBuffer) Scroll(amount int) {
+	db.RegionScroll(db.Bounds(), amount)
+}
+
+// RegionScroll implements gfx.RegionScroller.
+func (db *DoubleBuffer) RegionScroll(region image.Rectangle, amount int) {
+	region = region.Intersect(db.Bounds())
+	if region.Empty() || amount == 0 {
+		return
+	}
+	db.dirtyAdd(region)
+	Scroll(db.Drawer, region, amount)
+	db.ScrollFill.apply(db, region, image.Pt(0, amount))
+}
+
+// VectorScroll implements gfx.VectorScroller. If the back buffer is not a
+// VectorScroller, the software VectorScroll is used.
+func (db *DoubleBuffer) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = region.Intersect(db.Bounds())
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	db.dirtyAdd(region)
+	if vs, ok := db.Drawer.(VectorScroller); ok {
+		vs.VectorScroll(region, vector)
+	} else {
+		VectorScroll(db.Drawer, region, vector)
+	}
+	db.ScrollFill.apply(db, region, vector)
+}
+
+// Invalidate marks r as dirty, so that it is sent to the front on the next Flush.
+func (db *DoubleBuffer) Invalidate(r image.Rectangle) {
+	db.dirtyAdd(r)
+}
+
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+func (db *DoubleBuffer) DirtyRects() []image.Rectangle {
+	return db.dirty.Rects()
+}
+
+// Flush implements gfx.DoubleBufferer. Each dirty rectangle of the back buffer
+// is blitted to the front, which is then flushed too if it is a DoubleBufferer.
+func (db *DoubleBuffer) Flush() {
+	for _, r := range db.dirty.Rects() {
+		if sub, ok := subImage(db.Drawer, r); ok {
+			db.Front.Blit(sub, r.Min)
+		} else {
+			db.Front.Blit(window{db.Drawer, r}, r.Min)
+		}
+	}
+	db.dirty.Clear()
+
+	if front, ok := db.Front.(DoubleBufferer); ok {
+		front.Flush()
+	}
+}
+
+func (db *DoubleBuffer) dirtyAdd(rect image.Rectangle) {
+	db.dirty.Add(rect.Intersect(db.Bounds()))
+}
+
+// window is a view of part of an image, for images without a SubImage method.
+type window struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (w window) Bounds() image.Rectangle {
+	return w.rect
+}
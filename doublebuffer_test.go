@@ -0,0 +1,76 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// interface checks
+var (
+	_ DoubleBufferer = &DoubleBuffer{}
+	_ Blitter        = &DoubleBuffer{}
+	_ Filler         = &DoubleBuffer{}
+	_ Scroller       = &DoubleBuffer{}
+	_ RegionScroller = &DoubleBuffer{}
+	_ VectorScroller = &DoubleBuffer{}
+)
+
+// recordingBlitter is a front buffer that remembers where it was blitted to.
+type recordingBlitter struct {
+	*RGB565
+	blits []image.Rectangle
+}
+
+func (r *recordingBlitter) Blit(src image.Image, at image.Point) {
+	r.blits = append(r.blits, src.Bounds().Sub(src.Bounds().Min).Add(at))
+	r.RGB565.Blit(src, at)
+}
+
+func Test_DoubleBuffer(t *testing.T) {
+	rand.Seed(11)
+	bounds := image.Rect(0, 0, 32, 24)
+	backs := map[string]func() Drawer{
+		"RGB565": func() Drawer { return NewRGB565(bounds) },
+		"plain":  func() Drawer { return plainDrawer{image.NewRGBA(bounds)} },
+	}
+
+	for name, newBack := range backs {
+		front := &recordingBlitter{RGB565: NewRGB565(bounds)}
+		db := NewDoubleBuffer(newBack(), front)
+
+		for i := 0; i < 200; i++ {
+			c := color.RGBA{uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256)), 0xFF}
+			switch rand.Intn(4) {
+			case 0:
+				db.Set(rand.Intn(40)-4, rand.Intn(30)-3, c)
+			case 1:
+				db.Fill(randomRect(bounds), c)
+			case 2:
+				db.RegionScroll(randomRect(bounds), rand.Intn(9)-4)
+			case 3:
+				db.VectorScroll(randomRect(bounds), image.Pt(rand.Intn(9)-4, rand.Intn(9)-4))
+			}
+
+			if rand.Intn(10) == 0 {
+				front.blits = nil
+				dirty := append([]image.Rectangle(nil), db.DirtyRects()...)
+				db.Flush()
+				if len(front.blits) != len(dirty) {
+					t.Fatalf("%s: flushed %v, dirty was %v", name, front.blits, dirty)
+				}
+				for j := range dirty {
+					if front.blits[j] != dirty[j] {
+						t.Fatalf("%s: flushed %v, dirty was %v", name, front.blits, dirty)
+					}
+				}
+				forAllPix(bounds, func(x, y int) {
+					if front.At(x, y) != front.ColorModel().Convert(db.At(x, y)) {
+						t.Fatalf("%s: iteration %d: front and back differ at (%d,%d)", name, i, x, y)
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package font
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseBDF reads a font in Glyph Bitmap Distribution Format (BDF 2.1). Glyphs
+// with no encoding (ENCODING -1) are skipped. Fallback is set to the font's
+// DEFAULT_CHAR if it has one.
+func ParseBDF(r io.Reader) (*Font, error) {
+	f := &Font{Glyphs: make(map[rune]*Glyph)}
+
+	var (
+		haveAscent, haveDescent bool
+		fontBox                 image.Rectangle
+		g                       *Glyph
+		encoding                int
+		bitmapRows              int
+	)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// inside BITMAP, every line is a row of hex
+		if bitmapRows > 0 {
+			row := (g.Rect.Dy() - bitmapRows) * g.Stride
+			b, err := hex.DecodeString(fields[0])
+			if err != nil || len(b) < g.Stride {
+				return nil, fmt.Errorf("font: bdf line %d: bad bitmap row %q", line, fields[0])
+			}
+			copy(g.Bitmap[row:row+g.Stride], b)
+			bitmapRows--
+			continue
+		}
+
+		nums, err := atois(fields[1:])
+		bad := func(n int) bool {
+			return err != nil || len(nums) < n
+		}
+
+		switch fields[0] {
+		case "FONT":
+			f.Name = strings.Join(fields[1:], " ")
+		case "FONTBOUNDINGBOX":
+			if bad(4) {
+				return nil, fmt.Errorf("font: bdf line %d: bad FONTBOUNDINGBOX", line)
+			}
+			fontBox = bbx(nums)
+		case "FONT_ASCENT":
+			if bad(1) {
+				return nil, fmt.Errorf("font: bdf line %d: bad FONT_ASCENT", line)
+			}
+			f.Ascent, haveAscent = nums[0], true
+		case "FONT_DESCENT":
+			if bad(1) {
+				return nil, fmt.Errorf("font: bdf line %d: bad FONT_DESCENT", line)
+			}
+			f.Descent, haveDescent = nums[0], true
+		case "DEFAULT_CHAR":
+			if bad(1) {
+				return nil, fmt.Errorf("font: bdf line %d: bad DEFAULT_CHAR", line)
+			}
+			f.Fallback = rune(nums[0])
+		case "STARTCHAR":
+			g, encoding = &Glyph{}, -1
+		case "ENCODING":
+			if g == nil || bad(1) {
+				return nil, fmt.Errorf("font: bdf line %d: bad ENCODING", line)
+			}
+			encoding = nums[0]
+		case "DWIDTH":
+			if g == nil || bad(1) {
+				return nil, fmt.Errorf("font: bdf line %d: bad DWIDTH", line)
+			}
+			g.Advance = nums[0]
+		case "BBX":
+			if g == nil || bad(4) {
+				return nil, fmt.Errorf("font: bdf line %d: bad BBX", line)
+			}
+			g.Rect = bbx(nums)
+		case "BITMAP":
+			if g == nil {
+				return nil, fmt.Errorf("font: bdf line %d: BITMAP outside a character", line)
+			}
+			g.Stride = (g.Rect.Dx() + 7) / 8
+			g.Bitmap = make([]byte, g.Stride*g.Rect.Dy())
+			bitmapRows = g.Rect.Dy()
+		case "ENDCHAR":
+			if g == nil {
+				return nil, fmt.Errorf("font: bdf line %d: ENDCHAR outside a character", line)
+			}
+			if encoding >= 0 {
+				if g.Bitmap == nil {
+					g.Stride = (g.Rect.Dx() + 7) / 8
+					g.Bitmap = make([]byte, g.Stride*g.Rect.Dy())
+				}
+				f.Glyphs[rune(encoding)] = g
+			}
+			g = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if bitmapRows > 0 || g != nil {
+		return nil, fmt.Errorf("font: bdf: unexpected end of file")
+	}
+
+	if !haveAscent {
+		f.Ascent = -fontBox.Min.Y
+	}
+	if !haveDescent {
+		f.Descent = fontBox.Max.Y
+	}
+
+	return f, nil
+}
+
+// bbx turns BDF's width, height, x offset and y offset (up from the baseline)
+// into a rectangle relative to the dot.
+func bbx(n []int) image.Rectangle {
+	return image.Rect(n[2], -n[3]-n[1], n[2]+n[0], -n[3])
+}
+
+func atois(fields []string) ([]int, error) {
+	nums := make([]int, len(fields))
+	for i, s := range fields {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
@@ -0,0 +1,115 @@
+package font
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/sparques/gfx"
+)
+
+// GlyphCache keeps glyphs already drawn in their colors, so that drawing the
+// same rune again in the same colors is a single Blit rather than another
+// round of filling or blending. Entries are keyed by face, and so by size, as
+// well as by rune, foreground and background color, and whether the
+// destination is RGB565.
+//
+// Only runes drawn on a background are cached: without one, a glyph has to be
+// blended over whatever is already in the destination.
+//
+// The zero value is an empty cache with no limit.
+type GlyphCache struct {
+	// MaxEntries is the most glyphs kept; when it is reached, the oldest is
+	// dropped. Zero means no limit.
+	MaxEntries int
+
+	cells map[glyphKey]image.Image
+	order []glyphKey
+}
+
+type glyphKey struct {
+	face   Face
+	r      rune
+	fg, bg color.RGBA64
+	rgb565 bool
+}
+
+// NewGlyphCache returns an empty GlyphCache holding up to maxEntries glyphs.
+func NewGlyphCache(maxEntries int) *GlyphCache {
+	return &GlyphCache{
+		MaxEntries: maxEntries,
+		cells:      make(map[glyphKey]image.Image),
+	}
+}
+
+// Face returns f with its drawing going through the cache. f must be
+// comparable, as pointers are; one cache may serve any number of faces.
+func (c *GlyphCache) Face(f Face) Face {
+	return cachedFace{f, c}
+}
+
+// Len returns the number of glyphs in the cache.
+func (c *GlyphCache) Len() int {
+	return len(c.cells)
+}
+
+// Clear empties the cache.
+func (c *GlyphCache) Clear() {
+	clear(c.cells)
+	c.order = c.order[:0]
+}
+
+func (c *GlyphCache) add(key glyphKey, cell image.Image) {
+	if c.MaxEntries > 0 && len(c.order) >= c.MaxEntries {
+		delete(c.cells, c.order[0])
+		c.order = c.order[1:]
+	}
+	if c.cells == nil {
+		c.cells = make(map[glyphKey]image.Image)
+	}
+	c.cells[key] = cell
+	c.order = append(c.order, key)
+}
+
+// cachedFace is a Face drawing through a GlyphCache.
+type cachedFace struct {
+	Face
+	cache *GlyphCache
+}
+
+// DrawRune implements Face. With a background, the rune's cell is drawn once
+// into an image matching dst, and blitted from then on.
+func (f cachedFace) DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point {
+	if bg == nil {
+		return f.Face.DrawRune(dst, dot, r, fg, bg)
+	}
+
+	ascent, descent := f.Metrics()
+	advance := f.Advance(r)
+	rect := image.Rect(0, -ascent, advance, descent)
+	if rect.Empty() {
+		return dot.Add(image.Pt(advance, 0))
+	}
+
+	key := glyphKey{
+		face:   f.Face,
+		r:      r,
+		fg:     color.RGBA64Model.Convert(fg).(color.RGBA64),
+		bg:     color.RGBA64Model.Convert(bg).(color.RGBA64),
+		rgb565: dst.ColorModel() == gfx.RGB565BEModel,
+	}
+	cell, ok := f.cache.cells[key]
+	if !ok {
+		var buf gfx.Drawer
+		if key.rgb565 {
+			buf = gfx.NewRGB565(rect)
+		} else {
+			buf = image.NewRGBA(rect)
+		}
+		f.Face.DrawRune(buf, image.Point{}, r, fg, bg)
+		cell = buf
+		f.cache.add(key, cell)
+	}
+
+	gfx.Blit(dst, cell, dot.Add(rect.Min))
+	return dot.Add(image.Pt(advance, 0))
+}
@@ -0,0 +1,21 @@
+package font // github.com/sparques/gfx/font
+/*
+Package font loads bitmap fonts and draws text with them onto any gfx.Drawer.
+
+BDF and PC Screen Font (PSF1 and PSF2) files are parsed into a Font, an in-memory
+table of Glyphs. Glyphs are 1 bit per pixel and are drawn in a foreground color,
+optionally on a background, using the destination's Filler or Blitter when it
+has one.
+
+A Font's DrawCell method makes it usable as a term.Font, and CellSize gives the
+cell size to create a gfx.SoftScreen with.
+
+For smoother text, Rasterize shrinks a large Face into a MaskFont, whose
+MaskGlyphs are 4 or 8 bit alpha masks blended over the destination with
+gfx.BlendMask. A GlyphCache keeps glyphs drawn on a background, so that text
+which is redrawn often is blitted rather than blended again.
+
+ParseHershey reads a Hershey stroke font, whose glyphs are lines rather than
+pixels. A StrokeFace draws one at any size, angle and line thickness with the
+shape package's lines, without any bitmaps to store.
+*/
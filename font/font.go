@@ -0,0 +1,202 @@
+package font
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/sparques/gfx"
+)
+
+// Glyph is the bitmap and metrics of a single character. It is an image.Image
+// (an alpha mask, opaque where the glyph is inked) whose coordinates are
+// relative to the dot: the point on the baseline where the glyph is drawn.
+type Glyph struct {
+	// Bitmap holds one bit per pixel, the most significant bit leftmost, with
+	// each row padded to a whole byte.
+	Bitmap []byte
+	// Stride is the number of bytes per row of Bitmap.
+	Stride int
+	// Rect is the bitmap's position relative to the dot. Rows above the baseline
+	// have negative Y.
+	Rect image.Rectangle
+	// Advance is how far the dot moves right after drawing the glyph.
+	Advance int
+}
+
+func (g *Glyph) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (g *Glyph) Bounds() image.Rectangle {
+	return g.Rect
+}
+
+func (g *Glyph) At(x, y int) color.Color {
+	if g.Inked(x, y) {
+		return color.Alpha{0xFF}
+	}
+	return color.Alpha{0}
+}
+
+// Inked reports whether the pixel at x, y is part of the glyph.
+func (g *Glyph) Inked(x, y int) bool {
+	if !image.Pt(x, y).In(g.Rect) {
+		return false
+	}
+	x, y = x-g.Rect.Min.X, y-g.Rect.Min.Y
+	return g.Bitmap[y*g.Stride+x/8]&(0x80>>(x%8)) != 0
+}
+
+// Font is a table of Glyphs along with the metrics needed to lay them out.
+type Font struct {
+	// Name is the font's name, if the file gave one.
+	Name string
+	// Glyphs maps each rune to its glyph.
+	Glyphs map[rune]*Glyph
+	// Ascent and Descent are how far the font extends above and below the
+	// baseline. Their sum is the line height.
+	Ascent, Descent int
+	// Fallback is the rune whose glyph is drawn for runes the font lacks.
+	Fallback rune
+}
+
+// Glyph returns the glyph for r, or the Fallback glyph if there is none. It
+// returns nil if neither exists.
+func (f *Font) Glyph(r rune) *Glyph {
+	if g, ok := f.Glyphs[r]; ok {
+		return g
+	}
+	return f.Glyphs[f.Fallback]
+}
+
+// Metrics returns Ascent and Descent. It implements Face.
+func (f *Font) Metrics() (ascent, descent int) {
+	return f.Ascent, f.Descent
+}
+
+// Height returns the line height.
+func (f *Font) Height() int {
+	return f.Ascent + f.Descent
+}
+
+// Advance returns how far the dot moves after drawing r.
+func (f *Font) Advance(r rune) int {
+	if g := f.Glyph(r); g != nil {
+		return g.Advance
+	}
+	return 0
+}
+
+// Measure returns the width of s: the sum of its runes' advances. Fonts have
+// no kerning, so this is exact.
+func (f *Font) Measure(s string) int {
+	var w int
+	for _, r := range s {
+		w += f.Advance(r)
+	}
+	return w
+}
+
+// CellSize returns the smallest cell every glyph's advance fits in, which for a
+// monospace font is the size of its character cells.
+func (f *Font) CellSize() image.Point {
+	var w int
+	for _, g := range f.Glyphs {
+		w = max(w, g.Advance)
+	}
+	return image.Pt(w, f.Height())
+}
+
+// DrawString draws s with its baseline starting at dot and returns the dot
+// after the last rune. Glyphs are drawn in fg; if bg is not nil, each rune's
+// cell (its advance wide and the line height tall) is first filled with bg.
+func (f *Font) DrawString(dst gfx.Drawer, dot image.Point, s string, fg, bg color.Color) image.Point {
+	for _, r := range s {
+		dot = f.DrawRune(dst, dot, r, fg, bg)
+	}
+	return dot
+}
+
+// DrawRune draws r like DrawString and returns the dot after it.
+func (f *Font) DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point {
+	g := f.Glyph(r)
+	if g == nil {
+		return dot
+	}
+	cell := image.Rect(dot.X, dot.Y-f.Ascent, dot.X+g.Advance, dot.Y+f.Descent)
+	drawGlyph(dst, g, dot, cell, fg, bg)
+	return dot.Add(image.Pt(g.Advance, 0))
+}
+
+// DrawCell draws r in cell, which it fills with bg, with the glyph's top left
+// at the cell's. Nothing is drawn outside cell. It implements term.Font.
+func (f *Font) DrawCell(dst gfx.Drawer, cell image.Rectangle, r rune, fg, bg color.Color) {
+	g := f.Glyph(r)
+	if g == nil {
+		gfx.Fill(dst, cell, bg)
+		return
+	}
+	drawGlyph(dst, g, cell.Min.Add(image.Pt(0, f.Ascent)), cell, fg, bg)
+}
+
+// drawGlyph draws g with its dot at dot, clipped to clip, which is first filled
+// with bg if that is not nil.
+//
+// If dst is a Filler, each run of inked pixels is one Fill. Otherwise, if the
+// glyph is opaque and dst is a Blitter, the whole cell is blitted at once.
+func drawGlyph(dst gfx.Drawer, g *Glyph, dot image.Point, clip image.Rectangle, fg, bg color.Color) {
+	clip = clip.Intersect(dst.Bounds())
+	if clip.Empty() {
+		return
+	}
+
+	if _, ok := dst.(gfx.Filler); !ok && bg != nil {
+		if _, ok := dst.(gfx.Blitter); ok {
+			gfx.Blit(dst, colored{g, dot, clip, fg, bg}, clip.Min)
+			return
+		}
+	}
+
+	if bg != nil {
+		gfx.Fill(dst, clip, bg)
+	}
+
+	inked := g.Rect.Add(dot).Intersect(clip)
+	for y := inked.Min.Y; y < inked.Max.Y; y++ {
+		for x := inked.Min.X; x < inked.Max.X; x++ {
+			if !g.Inked(x-dot.X, y-dot.Y) {
+				continue
+			}
+			end := x + 1
+			for end < inked.Max.X && g.Inked(end-dot.X, y-dot.Y) {
+				end++
+			}
+			gfx.Fill(dst, image.Rect(x, y, end, y+1), fg)
+			x = end
+		}
+	}
+}
+
+// colored is a glyph drawn opaquely in fg on bg, positioned at dot and cut down
+// to rect, for blitting.
+type colored struct {
+	g      *Glyph
+	dot    image.Point
+	rect   image.Rectangle
+	fg, bg color.Color
+}
+
+func (c colored) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+func (c colored) Bounds() image.Rectangle {
+	return c.rect
+}
+
+func (c colored) At(x, y int) color.Color {
+	if c.g.Inked(x-c.dot.X, y-c.dot.Y) {
+		return c.fg
+	}
+	return c.bg
+}
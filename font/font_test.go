@@ -0,0 +1,207 @@
+package font
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/sparques/gfx"
+	"github.com/sparques/gfx/term"
+)
+
+// interface checks
+var (
+	_ term.Font   = &Font{}
+	_ image.Image = &Glyph{}
+)
+
+const testBDF = `STARTFONT 2.1
+FONT -test-tiny
+SIZE 4 75 75
+FONTBOUNDINGBOX 4 6 0 -1
+STARTPROPERTIES 3
+FONT_ASCENT 5
+FONT_DESCENT 1
+DEFAULT_CHAR 63
+ENDPROPERTIES
+CHARS 3
+STARTCHAR A
+ENCODING 65
+SWIDTH 500 0
+DWIDTH 4 0
+BBX 3 5 0 0
+BITMAP
+40
+A0
+E0
+A0
+A0
+ENDCHAR
+STARTCHAR question
+ENCODING 63
+DWIDTH 4 0
+BBX 3 3 0 2
+BITMAP
+E0
+20
+40
+ENDCHAR
+STARTCHAR unencoded
+ENCODING -1
+DWIDTH 4 0
+BBX 1 1 0 0
+BITMAP
+80
+ENDCHAR
+ENDFONT
+`
+
+// render draws s with f and returns it as rows of '#' and '.'.
+func render(dst gfx.Drawer, f *Font, s string) string {
+	gfx.Fill(dst, dst.Bounds(), color.White)
+	f.DrawString(dst, image.Pt(0, f.Ascent), s, color.Black, nil)
+
+	var sb strings.Builder
+	b := dst.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if r, _, _, _ := dst.At(x, y).RGBA(); r == 0 {
+				sb.WriteByte('#')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func Test_BDF(t *testing.T) {
+	f, err := ParseBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "-test-tiny" || f.Ascent != 5 || f.Descent != 1 || len(f.Glyphs) != 2 {
+		t.Errorf("got name %q, ascent %d, descent %d, %d glyphs", f.Name, f.Ascent, f.Descent, len(f.Glyphs))
+	}
+	if f.CellSize() != image.Pt(4, 6) || f.Measure("AAx") != 12 {
+		t.Errorf("cell size %v, measured %d", f.CellSize(), f.Measure("AAx"))
+	}
+
+	// "x" is missing, so is drawn as the DEFAULT_CHAR '?'
+	want := "" +
+		".#..###.###.\n" +
+		"#.#...#...#.\n" +
+		"###..#...#..\n" +
+		"#.#.........\n" +
+		"#.#.........\n" +
+		"............\n"
+	targets := map[string]gfx.Drawer{
+		"RGBA":     gfx.NewRGBA(image.NewRGBA(image.Rect(0, 0, 12, 6))),
+		"RGB565":   gfx.NewRGB565(image.Rect(0, 0, 12, 6)),
+		"Paletted": image.NewPaletted(image.Rect(0, 0, 12, 6), color.Palette{color.Black, color.White}),
+	}
+	for name, dst := range targets {
+		if got := render(dst, f, "A?x"); got != want {
+			t.Errorf("%s: got\n%swant\n%s", name, got, want)
+		}
+	}
+}
+
+// blitOnly is a Drawer with a Blit method but no Fill.
+type blitOnly struct {
+	draw.Image
+	blits int
+}
+
+func (b *blitOnly) Blit(src image.Image, at image.Point) {
+	b.blits++
+	draw.Draw(b.Image, src.Bounds().Sub(src.Bounds().Min).Add(at), src, src.Bounds().Min, draw.Src)
+}
+
+func Test_DrawCell(t *testing.T) {
+	f, _ := ParseBDF(strings.NewReader(testBDF))
+	dst := &blitOnly{Image: image.NewRGBA(image.Rect(0, 0, 8, 6))}
+	f.DrawCell(dst, image.Rect(0, 0, 4, 6), 'A', color.White, color.Black)
+	f.DrawCell(dst, image.Rect(4, 0, 8, 6), 'A', color.Black, color.White)
+	if dst.blits != 2 {
+		t.Errorf("drew cells with %d blits", dst.blits)
+	}
+	if dst.At(1, 0) != colorToRGBA(color.White) || dst.At(0, 0) != colorToRGBA(color.Black) ||
+		dst.At(5, 0) != colorToRGBA(color.Black) || dst.At(7, 5) != colorToRGBA(color.White) {
+		t.Error("cells drawn wrong")
+	}
+}
+
+func colorToRGBA(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// the glyph for 'A' in testBDF, as an 8x5 PSF glyph
+var psfA = []byte{0x40, 0xA0, 0xE0, 0xA0, 0xA0}
+
+func Test_PSF1(t *testing.T) {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x36, 0x04, psf1ModeHasTab, 5})
+	glyphs := make([]byte, 256*5)
+	copy(glyphs[1*5:], psfA)
+	buf.Write(glyphs)
+	// glyph 0 maps to nothing, glyph 1 to both 'A' and 'Ä' and the start of a
+	// sequence, and the table ends before the rest
+	binary.Write(&buf, binary.LittleEndian, []uint16{0xFFFF})
+	binary.Write(&buf, binary.LittleEndian, []uint16{'A', 'Ä', 0xFFFE, 'A', 0x0308, 0xFFFF})
+
+	f, err := ParsePSF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Glyphs) != 2 || f.Glyphs['A'] != f.Glyphs['Ä'] || f.CellSize() != image.Pt(8, 5) {
+		t.Errorf("got %d glyphs, cell size %v", len(f.Glyphs), f.CellSize())
+	}
+	want := ".#......\n#.#.....\n###.....\n#.#.....\n#.#.....\n"
+	if got := render(gfx.NewRGB565(image.Rect(0, 0, 8, 5)), f, "A"); got != want {
+		t.Errorf("got\n%swant\n%s", got, want)
+	}
+}
+
+func Test_PSF2(t *testing.T) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint32{psf2Magic, 0, 32, psf2HasUnicodeTable, 2, 10, 5, 12})
+	// 12 pixels wide is 2 bytes a row; the 'A' goes in the second glyph
+	glyphs := make([]byte, 2*10)
+	for i, b := range psfA {
+		glyphs[10+2*i] = b
+		glyphs[10+2*i+1] = 0x10
+	}
+	buf.Write(glyphs)
+	buf.WriteString("?\xff")
+	buf.WriteString("AÄ\xfeÄ\xff")
+
+	f, err := ParsePSF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Glyphs) != 3 || f.Glyphs['A'] != f.Glyphs['Ä'] || f.Advance('A') != 12 {
+		t.Errorf("got %d glyphs, advance %d", len(f.Glyphs), f.Advance('A'))
+	}
+	want := ".#.........#\n#.#........#\n###........#\n#.#........#\n#.#........#\n"
+	if got := render(gfx.NewRGB565(image.Rect(0, 0, 12, 5)), f, "A"); got != want {
+		t.Errorf("got\n%swant\n%s", got, want)
+	}
+}
+
+func Test_BadFonts(t *testing.T) {
+	if _, err := ParsePSF(strings.NewReader("nope")); err == nil {
+		t.Error("ParsePSF accepted garbage")
+	}
+	if _, err := ParsePSF(bytes.NewReader([]byte{0x36, 0x04, 0, 16, 1, 2, 3})); err == nil {
+		t.Error("ParsePSF accepted a truncated font")
+	}
+	if _, err := ParseBDF(strings.NewReader("STARTCHAR A\nBBX 1 1 0 0\nBITMAP\nzz\nENDCHAR\n")); err == nil {
+		t.Error("ParseBDF accepted a bad bitmap")
+	}
+}
@@ -0,0 +1,199 @@
+package font
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sparques/gfx"
+	"github.com/sparques/gfx/shape"
+)
+
+// hersheyBaseline is the Hershey y coordinate of the baseline in the roman
+// fonts; capitals reach from -12 down to it.
+const hersheyBaseline = 9
+
+// StrokeGlyph is a glyph made of lines rather than pixels, in font units.
+// Coordinates are relative to the dot, with y growing downwards, so rows above
+// the baseline have negative Y.
+type StrokeGlyph struct {
+	// Strokes are the polylines drawn with the pen down.
+	Strokes [][]image.Point
+	// Advance is how far the dot moves right after drawing the glyph.
+	Advance int
+}
+
+// StrokeFont is a table of StrokeGlyphs. It has no size of its own: a
+// StrokeFace draws it at a given size, angle and thickness.
+type StrokeFont struct {
+	// Glyphs maps each rune to its glyph.
+	Glyphs map[rune]*StrokeGlyph
+	// Ascent and Descent are how far the font extends above and below the
+	// baseline, in font units.
+	Ascent, Descent int
+	// Fallback is the rune whose glyph is drawn for runes the font lacks.
+	Fallback rune
+}
+
+// Glyph returns the glyph for r, or the Fallback glyph if there is none. It
+// returns nil if neither exists.
+func (f *StrokeFont) Glyph(r rune) *StrokeGlyph {
+	if g, ok := f.Glyphs[r]; ok {
+		return g
+	}
+	return f.Glyphs[f.Fallback]
+}
+
+// ParseHershey reads a font in the Hershey ".jhf" format: one glyph per
+// record, each a 5 column glyph number, a 3 column count of coordinate pairs,
+// then the pairs, with each coordinate a character offset from 'R'. The first
+// pair is the glyph's left and right extent and " R" lifts the pen. Records
+// may be wrapped over several lines.
+//
+// As in the usual .jhf files, glyphs are taken to be in rune order starting
+// at the space; glyph numbers are ignored. The Fallback is '?'.
+func ParseHershey(r io.Reader) (*StrokeFont, error) {
+	// records may wrap, so work on the text with the line breaks removed
+	var sb strings.Builder
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		sb.WriteString(strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	data := sb.String()
+
+	f := &StrokeFont{
+		Glyphs:   make(map[rune]*StrokeGlyph),
+		Fallback: '?',
+	}
+	for next := ' '; strings.TrimSpace(data) != ""; next++ {
+		if len(data) < 8 {
+			return nil, fmt.Errorf("font: truncated Hershey glyph %q", data)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(data[5:8]))
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("font: bad Hershey coordinate count %q", data[5:8])
+		}
+		if len(data) < 8+2*count {
+			return nil, fmt.Errorf("font: truncated Hershey glyph %q", data)
+		}
+		pairs := data[8 : 8+2*count]
+		data = data[8+2*count:]
+
+		left, right := int(pairs[0])-'R', int(pairs[1])-'R'
+		g := &StrokeGlyph{Advance: right - left}
+		var stroke []image.Point
+		for i := 2; i < len(pairs); i += 2 {
+			if pairs[i:i+2] == " R" {
+				if len(stroke) > 0 {
+					g.Strokes = append(g.Strokes, stroke)
+				}
+				stroke = nil
+				continue
+			}
+			p := image.Pt(int(pairs[i])-'R'-left, int(pairs[i+1])-'R'-hersheyBaseline)
+			stroke = append(stroke, p)
+			f.Ascent, f.Descent = max(f.Ascent, -p.Y), max(f.Descent, p.Y)
+		}
+		if len(stroke) > 0 {
+			g.Strokes = append(g.Strokes, stroke)
+		}
+		f.Glyphs[next] = g
+	}
+	return f, nil
+}
+
+// StrokeFace draws a StrokeFont scaled, rotated and with lines of a given
+// thickness. It implements Face, though when it is rotated only DrawString
+// keeps to the rotated baseline exactly: DrawRune must round the dot it
+// returns.
+type StrokeFace struct {
+	Font *StrokeFont
+	// Size is the line height in pixels.
+	Size float64
+	// Angle rotates the text counterclockwise about the dot, in radians.
+	Angle float64
+	// Thickness is the width of the lines in pixels; 0 is 1.
+	Thickness int
+}
+
+func (f *StrokeFace) scale() float64 {
+	return f.Size / float64(f.Font.Ascent+f.Font.Descent)
+}
+
+// Metrics returns how far the face extends above and below the baseline,
+// rounded up to whole pixels.
+func (f *StrokeFace) Metrics() (ascent, descent int) {
+	s := f.scale()
+	return int(math.Ceil(float64(f.Font.Ascent) * s)), int(math.Ceil(float64(f.Font.Descent) * s))
+}
+
+// Advance returns how far the dot moves after drawing r, rounded to the
+// nearest pixel.
+func (f *StrokeFace) Advance(r rune) int {
+	if g := f.Font.Glyph(r); g != nil {
+		return int(math.Round(float64(g.Advance) * f.scale()))
+	}
+	return 0
+}
+
+// DrawString draws s in c with its baseline starting at dot and heading along
+// Angle, and returns the dot after the last rune.
+func (f *StrokeFace) DrawString(dst gfx.Drawer, dot image.Point, s string, c color.Color) image.Point {
+	x, y := float64(dot.X), float64(dot.Y)
+	for _, r := range s {
+		x, y = f.draw(dst, x, y, r, c)
+	}
+	return image.Pt(int(math.Round(x)), int(math.Round(y)))
+}
+
+// DrawRune draws r in fg with its dot at dot, and returns the dot after it. If
+// bg is not nil and the face is not rotated, the rune's cell is filled with it
+// first; rotated cells are not filled.
+func (f *StrokeFace) DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point {
+	if bg != nil && f.Angle == 0 {
+		ascent, descent := f.Metrics()
+		gfx.Fill(dst, image.Rect(dot.X, dot.Y-ascent, dot.X+f.Advance(r), dot.Y+descent), bg)
+	}
+	x, y := f.draw(dst, float64(dot.X), float64(dot.Y), r, fg)
+	return image.Pt(int(math.Round(x)), int(math.Round(y)))
+}
+
+// draw draws r with its dot at x, y and returns the dot after it.
+func (f *StrokeFace) draw(dst gfx.Drawer, x, y float64, r rune, c color.Color) (float64, float64) {
+	g := f.Font.Glyph(r)
+	if g == nil {
+		return x, y
+	}
+
+	s := f.scale()
+	sin, cos := math.Sincos(f.Angle)
+	// place maps font units to pixels; y grows downwards, so counterclockwise
+	// takes +x towards -y
+	place := func(p image.Point) image.Point {
+		px, py := float64(p.X)*s, float64(p.Y)*s
+		return image.Pt(int(math.Round(x+px*cos+py*sin)), int(math.Round(y-px*sin+py*cos)))
+	}
+
+	for _, stroke := range g.Strokes {
+		from := place(stroke[0])
+		if len(stroke) == 1 {
+			shape.ThickLine(dst, from, from, f.Thickness, c)
+		}
+		for _, p := range stroke[1:] {
+			to := place(p)
+			shape.ThickLine(dst, from, to, f.Thickness, c)
+			from = to
+		}
+	}
+
+	advance := float64(g.Advance) * s
+	return x + advance*cos, y - advance*sin
+}
@@ -0,0 +1,60 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+// interface checks
+var _ Face = &StrokeFace{}
+
+// a space and the simplex roman '!', with the '!' record wrapped
+const testHershey = "" +
+	"12345  1JZ\n" +
+	"  714  9MWRFRT RRYQZR\n" +
+	"[SZRY\n"
+
+func Test_Hershey(t *testing.T) {
+	f, err := ParseHershey(strings.NewReader(testHershey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Glyphs) != 2 || f.Ascent != 21 || f.Descent != 0 {
+		t.Fatalf("got %d glyphs, ascent %d, descent %d", len(f.Glyphs), f.Ascent, f.Descent)
+	}
+	g := f.Glyph('!')
+	if g.Advance != 10 || len(g.Strokes) != 2 || g.Strokes[0][1] != image.Pt(5, -7) || f.Glyph(' ').Advance != 16 {
+		t.Errorf("got %+v", g)
+	}
+
+	// at one pixel a unit, the '!' is a vertical line from (5,0) to (5,14)
+	face := &StrokeFace{Font: f, Size: 21}
+	dst := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	if dot := face.DrawString(dst, image.Pt(0, 21), "!", color.White); dot != image.Pt(10, 21) {
+		t.Errorf("dot ended at %v", dot)
+	}
+	if dst.RGBAAt(5, 10).A == 0 || dst.RGBAAt(4, 10).A != 0 || dst.RGBAAt(5, 15).A != 0 {
+		t.Error("upright '!' drawn wrong")
+	}
+
+	// turned a quarter counterclockwise it runs left to right, and text heads up
+	face.Angle, face.Thickness = math.Pi/2, 3
+	dst = image.NewRGBA(image.Rect(0, 0, 40, 40))
+	if dot := face.DrawString(dst, image.Pt(30, 30), "!", color.White); dot != image.Pt(30, 20) {
+		t.Errorf("rotated dot ended at %v", dot)
+	}
+	if dst.RGBAAt(15, 25).A == 0 || dst.RGBAAt(15, 26).A == 0 || dst.RGBAAt(15, 28).A != 0 {
+		t.Error("rotated '!' drawn wrong")
+	}
+
+	if face.Advance('!') != 10 {
+		t.Errorf("advance %d", face.Advance('!'))
+	}
+
+	if _, err := ParseHershey(strings.NewReader("    1  3JZRF")); err == nil {
+		t.Error("ParseHershey accepted a truncated glyph")
+	}
+}
@@ -0,0 +1,265 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sparques/gfx"
+)
+
+// Face is a source of glyphs that text can be laid out with. *Font is one.
+type Face interface {
+	// Metrics returns how far the face extends above and below the baseline.
+	Metrics() (ascent, descent int)
+	// Advance returns how far the dot moves after drawing r.
+	Advance(r rune) int
+	// DrawRune draws r with its dot at dot, in fg on bg (if bg is not nil),
+	// and returns the dot after it.
+	DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point
+}
+
+// Align is where text is placed within a box, horizontally or vertically.
+type Align int
+
+const (
+	// AlignStart puts text at the left or top.
+	AlignStart Align = iota
+	// AlignCenter centers text.
+	AlignCenter
+	// AlignEnd puts text at the right or bottom.
+	AlignEnd
+)
+
+// Layout lays out and draws text in a box. Explicit newlines always start a new
+// line; with Wrap set, lines are also broken at spaces to fit the box's width,
+// and words too long to fit on a line are broken between runes.
+//
+// Whatever does not fit in the box is clipped. If Ellipsis is set, it replaces
+// the end of each line that is too wide, and of the last line that fits when
+// there are more lines than fit.
+type Layout struct {
+	Face Face
+	// Align and VAlign place the text horizontally and vertically in the box.
+	Align, VAlign Align
+	// Wrap breaks lines to fit the width of the box.
+	Wrap bool
+	// TabWidth is the distance between tab stops in pixels. Zero means the
+	// width of 8 spaces.
+	TabWidth int
+	// LineSpacing is added between lines, on top of the face's line height.
+	LineSpacing int
+	// Ellipsis, if not empty, marks text cut short by the box, for example "…".
+	Ellipsis string
+}
+
+// lineHeight returns the distance from one baseline to the next.
+func (l *Layout) lineHeight() int {
+	ascent, descent := l.Face.Metrics()
+	return ascent + descent + l.LineSpacing
+}
+
+// advance returns the advance of r when the dot is x pixels into a line. That
+// only matters for tabs.
+func (l *Layout) advance(r rune, x int) int {
+	if r != '\t' {
+		return l.Face.Advance(r)
+	}
+	tab := l.TabWidth
+	if tab <= 0 {
+		tab = 8 * l.Face.Advance(' ')
+	}
+	if tab <= 0 {
+		return 0
+	}
+	return (x/tab+1)*tab - x
+}
+
+// Measure returns the width of a single line of text.
+func (l *Layout) Measure(line string) int {
+	var x int
+	for _, r := range line {
+		x += l.advance(r, x)
+	}
+	return x
+}
+
+// Lines splits s into the lines it is drawn as in a box width pixels wide.
+// Trailing spaces are dropped from wrapped lines, and leading spaces from the
+// lines following them.
+func (l *Layout) Lines(s string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		if !l.Wrap {
+			lines = append(lines, para)
+			continue
+		}
+		for {
+			line, rest := l.wrap(para, width)
+			lines = append(lines, line)
+			if rest == "" {
+				break
+			}
+			para = rest
+		}
+	}
+	return lines
+}
+
+// wrap splits off as much of para as fits in width, preferring to break at a
+// space. At least one rune is always taken.
+func (l *Layout) wrap(para string, width int) (line, rest string) {
+	var x int
+	// brk is where the last run of spaces following a word starts
+	brk, inWord := -1, false
+	for i, r := range para {
+		space := r == ' ' || r == '\t'
+		if space && inWord {
+			brk = i
+		}
+		inWord = !space
+
+		adv := l.advance(r, x)
+		if x+adv > width && i > 0 {
+			end := i
+			if !space && brk > 0 {
+				end = brk
+			}
+			return strings.TrimRight(para[:end], " \t"), strings.TrimLeft(para[end:], " \t")
+		}
+		x += adv
+	}
+	return para, ""
+}
+
+// Size returns the size of the block s is laid out as, in a box width pixels
+// wide, without clipping to any height.
+func (l *Layout) Size(s string, width int) image.Point {
+	lines := l.Lines(s, width)
+	var w int
+	for _, line := range lines {
+		w = max(w, l.Measure(line))
+	}
+	return image.Pt(w, len(lines)*l.lineHeight()-l.LineSpacing)
+}
+
+// ellipsize shortens line so that it, followed by Ellipsis, fits in width.
+func (l *Layout) ellipsize(line string, width int) string {
+	width -= l.Measure(l.Ellipsis)
+	for line != "" && l.Measure(line) > width {
+		_, size := utf8.DecodeLastRuneInString(line)
+		line = line[:len(line)-size]
+	}
+	return strings.TrimRight(line, " \t") + l.Ellipsis
+}
+
+// Draw lays out s in box and draws it in fg, filling box with bg first if bg is
+// not nil. Nothing is drawn outside box. It reports whether any of the text
+// did not fit.
+func (l *Layout) Draw(dst gfx.Drawer, box image.Rectangle, s string, fg, bg color.Color) (overflow bool) {
+	if bg != nil {
+		gfx.Fill(dst, box, bg)
+	}
+
+	lines := l.Lines(s, box.Dx())
+	lineHeight := l.lineHeight()
+	ascent, _ := l.Face.Metrics()
+
+	// drop the lines that won't fit, ellipsizing the last one that does
+	if fit := max((box.Dy()+l.LineSpacing)/lineHeight, 0); len(lines) > fit {
+		overflow = true
+		lines = lines[:fit]
+		if fit > 0 && l.Ellipsis != "" {
+			lines[fit-1] = l.ellipsize(lines[fit-1], box.Dx())
+		}
+	}
+
+	y := box.Min.Y
+	height := len(lines)*lineHeight - l.LineSpacing
+	switch l.VAlign {
+	case AlignCenter:
+		y += (box.Dy() - height) / 2
+	case AlignEnd:
+		y = box.Max.Y - height
+	}
+
+	dst = clipped{dst, box}
+	for _, line := range lines {
+		width := l.Measure(line)
+		if width > box.Dx() {
+			overflow = true
+			if l.Ellipsis != "" {
+				line = l.ellipsize(line, box.Dx())
+				width = l.Measure(line)
+			}
+		}
+
+		x := box.Min.X
+		switch l.Align {
+		case AlignCenter:
+			x += (box.Dx() - width) / 2
+		case AlignEnd:
+			x = box.Max.X - width
+		}
+
+		dot := image.Pt(x, y+ascent)
+		for _, r := range line {
+			if r == '\t' {
+				dot.X += l.advance(r, dot.X-x)
+				continue
+			}
+			// passing bg on lets the face draw whole cells, which a GlyphCache
+			// can keep
+			dot = l.Face.DrawRune(dst, dot, r, fg, bg)
+		}
+		y += lineHeight
+	}
+
+	return overflow
+}
+
+// clipped is a Drawer which drops everything drawn outside rect.
+type clipped struct {
+	gfx.Drawer
+	rect image.Rectangle
+}
+
+func (c clipped) Bounds() image.Rectangle {
+	return c.rect.Intersect(c.Drawer.Bounds())
+}
+
+func (c clipped) Set(x, y int, col color.Color) {
+	if image.Pt(x, y).In(c.rect) {
+		c.Drawer.Set(x, y, col)
+	}
+}
+
+// Fill implements gfx.Filler.
+func (c clipped) Fill(r image.Rectangle, col color.Color) {
+	gfx.Fill(c.Drawer, r.Intersect(c.rect), col)
+}
+
+// Blit implements gfx.Blitter.
+func (c clipped) Blit(src image.Image, at image.Point) {
+	dst := src.Bounds().Sub(src.Bounds().Min).Add(at)
+	keep := dst.Intersect(c.rect)
+	if keep.Empty() {
+		return
+	}
+	if keep != dst {
+		sp := src.Bounds().Min.Add(keep.Min.Sub(at))
+		src = window{src, image.Rectangle{sp, sp.Add(keep.Size())}}
+	}
+	gfx.Blit(c.Drawer, src, keep.Min)
+}
+
+// window is a view of part of an image.
+type window struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (w window) Bounds() image.Rectangle {
+	return w.rect
+}
@@ -0,0 +1,128 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// interface checks
+var _ Face = &Font{}
+
+// pixelFace draws each rune as a single pixel, whose red channel holds the rune.
+type pixelFace struct{}
+
+func (pixelFace) Metrics() (int, int) { return 1, 0 }
+
+func (pixelFace) Advance(r rune) int { return 1 }
+
+func (pixelFace) DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point {
+	dst.Set(dot.X, dot.Y-1, color.RGBA{uint8(r), 0, 0, 0xFF})
+	return dot.Add(image.Pt(1, 0))
+}
+
+// drawLayout lays s out in a w by h box in the middle of a larger image, and
+// returns the box's contents with blank pixels as '.'. It fails if anything is
+// drawn outside the box.
+func drawLayout(t *testing.T, l *Layout, s string, w, h int) string {
+	t.Helper()
+	dst := image.NewRGBA(image.Rect(0, 0, w+4, h+4))
+	box := image.Rect(2, 2, 2+w, 2+h)
+	l.Draw(dst, box, s, color.White, nil)
+
+	var sb strings.Builder
+	for y := 0; y < h+4; y++ {
+		for x := 0; x < w+4; x++ {
+			c := dst.RGBAAt(x, y)
+			switch {
+			case c.A == 0 && image.Pt(x, y).In(box):
+				sb.WriteByte('.')
+			case c.A == 0:
+			case image.Pt(x, y).In(box):
+				sb.WriteByte(c.R)
+			default:
+				t.Fatalf("drew outside the box at (%d,%d)", x, y)
+			}
+		}
+		if y >= 2 && y < h+2 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+func Test_LayoutLines(t *testing.T) {
+	l := &Layout{Face: pixelFace{}, Wrap: true}
+	tests := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"the quick brown fox", 9, []string{"the quick", "brown fox"}},
+		{"the quick brown fox", 5, []string{"the", "quick", "brown", "fox"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"one\n\ntwo  three", 7, []string{"one", "", "two", "three"}},
+		{"  indented", 20, []string{"  indented"}},
+	}
+	for _, test := range tests {
+		if got := l.Lines(test.in, test.width); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q in %d: got %q, want %q", test.in, test.width, got, test.want)
+		}
+	}
+
+	if got := l.Measure("a\tb"); got != 9 {
+		t.Errorf("tab measured as %d", got)
+	}
+	if got := l.Size("the quick brown fox", 10); got != image.Pt(9, 2) {
+		t.Errorf("size %v", got)
+	}
+}
+
+func Test_LayoutDraw(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout Layout
+		in     string
+		want   string
+	}{
+		{"left top", Layout{Wrap: true}, "ab cdef", "ab....\ncdef..\n......\n"},
+		{"center middle", Layout{Wrap: true, Align: AlignCenter, VAlign: AlignCenter}, "ab cdef", "..ab..\n.cdef.\n......\n"},
+		{"right bottom", Layout{Wrap: true, Align: AlignEnd, VAlign: AlignEnd}, "ab cdef", "......\n....ab\n..cdef\n"},
+		{"clip", Layout{}, "abcdefgh\n1\n2\n3", "abcdef\n1.....\n2.....\n"},
+		{"ellipsis width", Layout{Ellipsis: "~"}, "abcdefgh", "abcde~\n......\n......\n"},
+		{"ellipsis height", Layout{Wrap: true, Ellipsis: "~"}, "aa bb cc dd ee ff gg", "aa bb.\ncc dd.\nee ff~\n"},
+		{"ellipsis lines", Layout{Ellipsis: "~"}, "a\nb\nc\nd", "a.....\nb.....\nc~....\n"},
+		{"tab", Layout{TabWidth: 4}, "a\tb", "a...b.\n......\n......\n"},
+		{"spacing", Layout{LineSpacing: 1}, "a\nb\nc", "a.....\n......\nb.....\n"},
+	}
+
+	for _, test := range tests {
+		test.layout.Face = pixelFace{}
+		if got := drawLayout(t, &test.layout, test.in, 6, 3); got != test.want {
+			t.Errorf("%s: got\n%swant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+// Test_LayoutFont checks Layout with a real font and a framebuffer, clipping
+// glyphs that straddle the edge of the box.
+func Test_LayoutFont(t *testing.T) {
+	f, err := ParseBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := gfx.NewRGB565(image.Rect(0, 0, 12, 6))
+	l := &Layout{Face: f}
+	if !l.Draw(dst, image.Rect(0, 0, 6, 6), "AA", color.White, color.Black) {
+		t.Error("no overflow reported")
+	}
+	white := dst.ColorModel().Convert(color.White)
+	if dst.At(4, 1) != white || dst.At(6, 1) == white {
+		t.Error("second glyph not clipped to the box")
+	}
+}
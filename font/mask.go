@@ -0,0 +1,210 @@
+package font
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/sparques/gfx"
+)
+
+// MaskGlyph is an anti-aliased glyph: an alpha mask holding how much of each
+// pixel the glyph covers, at 4 or 8 bits per pixel. Like Glyph, its coordinates
+// are relative to the dot. It implements gfx.AlphaMask.
+type MaskGlyph struct {
+	// Pix holds the coverage of each pixel, row by row. At 4 bits per pixel the
+	// leftmost of each pair of pixels is in the high nibble, and each row is
+	// padded to a whole byte.
+	Pix []byte
+	// Stride is the number of bytes per row of Pix.
+	Stride int
+	// Depth is the number of bits per pixel, 4 or 8.
+	Depth int
+	// Rect is the mask's position relative to the dot.
+	Rect image.Rectangle
+	// Advance is how far the dot moves right after drawing the glyph.
+	Advance int
+}
+
+func (g *MaskGlyph) ColorModel() color.Model {
+	return color.AlphaModel
+}
+
+func (g *MaskGlyph) Bounds() image.Rectangle {
+	return g.Rect
+}
+
+func (g *MaskGlyph) At(x, y int) color.Color {
+	return g.AlphaAt(x, y)
+}
+
+// AlphaAt returns the coverage at x, y, scaled up to 8 bits.
+func (g *MaskGlyph) AlphaAt(x, y int) color.Alpha {
+	if !image.Pt(x, y).In(g.Rect) {
+		return color.Alpha{}
+	}
+	x, y = x-g.Rect.Min.X, y-g.Rect.Min.Y
+	if g.Depth == 4 {
+		v := g.Pix[y*g.Stride+x/2] >> (4 * (1 - x%2)) & 0xF
+		return color.Alpha{v * 0x11}
+	}
+	return color.Alpha{g.Pix[y*g.Stride+x]}
+}
+
+// MaskFont is a table of MaskGlyphs, all rasterized at one size, along with
+// the metrics needed to lay them out. Its glyphs are blended over whatever is
+// already drawn, so they need not be drawn on a background.
+type MaskFont struct {
+	// Name is the font's name.
+	Name string
+	// Glyphs maps each rune to its glyph.
+	Glyphs map[rune]*MaskGlyph
+	// Ascent and Descent are how far the font extends above and below the
+	// baseline. Their sum is the line height.
+	Ascent, Descent int
+	// Fallback is the rune whose glyph is drawn for runes the font lacks.
+	Fallback rune
+}
+
+// Glyph returns the glyph for r, or the Fallback glyph if there is none. It
+// returns nil if neither exists.
+func (f *MaskFont) Glyph(r rune) *MaskGlyph {
+	if g, ok := f.Glyphs[r]; ok {
+		return g
+	}
+	return f.Glyphs[f.Fallback]
+}
+
+// Metrics returns Ascent and Descent. It implements Face.
+func (f *MaskFont) Metrics() (ascent, descent int) {
+	return f.Ascent, f.Descent
+}
+
+// Height returns the line height.
+func (f *MaskFont) Height() int {
+	return f.Ascent + f.Descent
+}
+
+// Advance returns how far the dot moves after drawing r.
+func (f *MaskFont) Advance(r rune) int {
+	if g := f.Glyph(r); g != nil {
+		return g.Advance
+	}
+	return 0
+}
+
+// DrawString draws s with its baseline starting at dot and returns the dot
+// after the last rune. Glyphs are blended in fg over dst; if bg is not nil,
+// each rune's cell is first filled with bg.
+func (f *MaskFont) DrawString(dst gfx.Drawer, dot image.Point, s string, fg, bg color.Color) image.Point {
+	for _, r := range s {
+		dot = f.DrawRune(dst, dot, r, fg, bg)
+	}
+	return dot
+}
+
+// DrawRune draws r like DrawString and returns the dot after it.
+func (f *MaskFont) DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point {
+	g := f.Glyph(r)
+	if g == nil {
+		return dot
+	}
+	if bg != nil {
+		gfx.Fill(dst, image.Rect(dot.X, dot.Y-f.Ascent, dot.X+g.Advance, dot.Y+f.Descent), bg)
+	}
+	gfx.BlendMask(dst, dot.Add(g.Rect.Min), g, fg)
+	return dot.Add(image.Pt(g.Advance, 0))
+}
+
+// DrawCell draws r in cell, which it fills with bg, with the glyph's top left
+// at the cell's. Nothing is drawn outside cell. It implements term.Font.
+func (f *MaskFont) DrawCell(dst gfx.Drawer, cell image.Rectangle, r rune, fg, bg color.Color) {
+	gfx.Fill(dst, cell, bg)
+	g := f.Glyph(r)
+	if g == nil {
+		return
+	}
+	dot := cell.Min.Add(image.Pt(0, f.Ascent))
+	visible := g.Rect.Add(dot).Intersect(cell)
+	if visible.Empty() {
+		return
+	}
+	gfx.BlendMask(dst, visible.Min, window{g, visible.Sub(dot)}, fg)
+}
+
+// Rasterize converts face into a MaskFont with glyphs for each rune in runes.
+// Each glyph is drawn factor times larger than wanted (face should be that
+// large) and shrunk by averaging each factor by factor block of pixels into
+// one, which gives the edges their shades of coverage. Depth is the bits per
+// pixel of the masks, 4 or 8.
+//
+// Glyphs are clipped to their cells: their advance wide and the line height
+// tall. Metrics are divided by factor, rounding the ascent and descent up and
+// advances to the nearest pixel. The font's Fallback is '?'.
+func Rasterize(face Face, runes string, factor, depth int) *MaskFont {
+	if factor < 1 {
+		panic("font: rasterize factor must be positive")
+	}
+	if depth != 4 && depth != 8 {
+		panic("font: rasterize depth must be 4 or 8")
+	}
+
+	ascent, descent := face.Metrics()
+	f := &MaskFont{
+		Glyphs:   make(map[rune]*MaskGlyph),
+		Ascent:   ceilDiv(ascent, factor),
+		Descent:  ceilDiv(descent, factor),
+		Fallback: '?',
+	}
+
+	for _, r := range runes {
+		if _, ok := f.Glyphs[r]; ok {
+			continue
+		}
+		advance := face.Advance(r)
+		rect := image.Rect(0, -f.Ascent, ceilDiv(advance, factor), f.Descent)
+		big := image.NewAlpha(image.Rectangle{rect.Min.Mul(factor), rect.Max.Mul(factor)})
+		face.DrawRune(big, image.Point{}, r, color.Opaque, nil)
+		f.Glyphs[r] = shrink(big, rect, factor, depth, (advance+factor/2)/factor)
+	}
+	return f
+}
+
+// shrink averages each factor by factor block of big into one pixel of a
+// MaskGlyph covering rect.
+func shrink(big *image.Alpha, rect image.Rectangle, factor, depth, advance int) *MaskGlyph {
+	g := &MaskGlyph{
+		Stride:  (rect.Dx()*depth + 7) / 8,
+		Depth:   depth,
+		Rect:    rect,
+		Advance: advance,
+	}
+	g.Pix = make([]byte, g.Stride*rect.Dy())
+
+	area := factor * factor
+	for y := 0; y < rect.Dy(); y++ {
+		for x := 0; x < rect.Dx(); x++ {
+			var sum int
+			for by := 0; by < factor; by++ {
+				i := big.PixOffset((rect.Min.X+x)*factor, (rect.Min.Y+y)*factor+by)
+				for _, a := range big.Pix[i : i+factor] {
+					sum += int(a)
+				}
+			}
+			v := (sum + area/2) / area
+			if depth == 4 {
+				g.Pix[y*g.Stride+x/2] |= byte((v*15+127)/255) << (4 * (1 - x%2))
+			} else {
+				g.Pix[y*g.Stride+x] = byte(v)
+			}
+		}
+	}
+	return g
+}
+
+// ceilDiv returns a/b rounded towards positive infinity, for positive b.
+func ceilDiv(a, b int) int {
+	if a >= 0 {
+		return (a + b - 1) / b
+	}
+	return -(-a / b)
+}
@@ -0,0 +1,138 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/sparques/gfx"
+	"github.com/sparques/gfx/term"
+)
+
+// interface checks
+var (
+	_ term.Font     = &MaskFont{}
+	_ Face          = &MaskFont{}
+	_ gfx.AlphaMask = &MaskGlyph{}
+)
+
+func Test_Rasterize(t *testing.T) {
+	bitmap, err := ParseBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'A' is 4x6 with the baseline 5 rows down; halved, each pixel is the
+	// average of a 2x2 block
+	want := [][]uint8{
+		{64, 0},
+		{191, 128},
+		{128, 128},
+		{0, 0},
+	}
+	for _, depth := range []int{8, 4} {
+		f := Rasterize(bitmap, "AA?", 2, depth)
+		if len(f.Glyphs) != 2 || f.Ascent != 3 || f.Descent != 1 || f.Advance('A') != 2 {
+			t.Errorf("depth %d: got %d glyphs, ascent %d, descent %d, advance %d", depth, len(f.Glyphs), f.Ascent, f.Descent, f.Advance('A'))
+		}
+		g := f.Glyph('A')
+		if g.Rect != image.Rect(0, -3, 2, 1) {
+			t.Fatalf("depth %d: glyph rect %v", depth, g.Rect)
+		}
+		for y, row := range want {
+			for x, a := range row {
+				if depth == 4 {
+					a = uint8((int(a)*15+127)/255) * 0x11
+				}
+				if got := g.AlphaAt(x, y-3).A; got != a {
+					t.Errorf("depth %d: coverage at (%d,%d) is %d, want %d", depth, x, y-3, got, a)
+				}
+			}
+		}
+	}
+}
+
+func Test_MaskFontDraw(t *testing.T) {
+	bitmap, _ := ParseBDF(strings.NewReader(testBDF))
+	f := Rasterize(bitmap, "A?", 2, 8)
+
+	dst := gfx.NewRGBA(image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	gfx.Fill(dst, dst.Bounds(), color.White)
+	dot := f.DrawString(dst, image.Pt(0, f.Ascent), "Ax", color.Black, nil)
+	if dot != image.Pt(4, 3) {
+		t.Errorf("dot ended at %v", dot)
+	}
+	// 64/255 coverage of black over white, and the fallback '?' drawn for 'x'
+	if got := dst.RGBA.RGBAAt(0, 0); got.R != 0xFF-64 || got.A != 0xFF {
+		t.Errorf("blended pixel is %v", got)
+	}
+	if dst.At(2, 0) == dst.At(3, 3) {
+		t.Error("fallback glyph not drawn")
+	}
+
+	// DrawCell stays inside the cell
+	cell := gfx.NewRGB565(image.Rect(0, 0, 4, 4))
+	f.DrawCell(cell, image.Rect(1, 1, 3, 3), 'A', color.White, color.Black)
+	black := cell.ColorModel().Convert(color.Black)
+	if cell.At(0, 0) != black || cell.At(3, 3) != black || cell.At(1, 2) == black {
+		t.Error("cell drawn wrong")
+	}
+}
+
+// countingFace counts the runes drawn through it.
+type countingFace struct {
+	Face
+	draws int
+}
+
+func (c *countingFace) DrawRune(dst gfx.Drawer, dot image.Point, r rune, fg, bg color.Color) image.Point {
+	c.draws++
+	return c.Face.DrawRune(dst, dot, r, fg, bg)
+}
+
+func Test_GlyphCache(t *testing.T) {
+	bitmap, _ := ParseBDF(strings.NewReader(testBDF))
+	counted := &countingFace{Face: Rasterize(bitmap, "A?", 2, 4)}
+	cache := NewGlyphCache(2)
+	face := cache.Face(counted)
+
+	want := gfx.NewRGB565(image.Rect(0, 0, 8, 4))
+	got := gfx.NewRGB565(image.Rect(0, 0, 8, 4))
+	counted.Face.DrawRune(want, image.Pt(0, 3), 'A', color.White, color.Black)
+	counted.Face.DrawRune(want, image.Pt(2, 3), 'A', color.White, color.Black)
+	counted.Face.DrawRune(want, image.Pt(4, 3), '?', color.White, color.Black)
+	(&Layout{Face: face}).Draw(got, got.Bounds(), "AA?", color.White, color.Black)
+
+	if counted.draws != 2 || cache.Len() != 2 {
+		t.Errorf("drew %d glyphs to cache %d", counted.draws, cache.Len())
+	}
+	if string(got.Pix) != string(want.Pix) {
+		t.Error("cached glyphs differ from drawing them directly")
+	}
+
+	// another color is another entry, pushing out the oldest
+	face.DrawRune(got, image.Pt(0, 3), 'A', color.Black, color.White)
+	face.DrawRune(got, image.Pt(0, 3), '?', color.White, color.Black)
+	face.DrawRune(got, image.Pt(0, 3), 'A', color.White, color.Black)
+	if counted.draws != 4 || cache.Len() != 2 {
+		t.Errorf("drew %d glyphs to cache %d", counted.draws, cache.Len())
+	}
+
+	// without a background nothing is cached
+	face.DrawRune(got, image.Pt(0, 3), 'A', color.White, nil)
+	cache.Clear()
+	if counted.draws != 5 || cache.Len() != 0 {
+		t.Errorf("drew %d glyphs to cache %d", counted.draws, cache.Len())
+	}
+	// the zero value works too, without a limit
+	var zero GlyphCache
+	face = zero.Face(counted)
+	face.DrawRune(got, image.Pt(0, 3), 'A', color.White, color.Black)
+	face.DrawRune(got, image.Pt(0, 3), 'A', color.White, color.Black)
+	face.DrawRune(got, image.Pt(0, 3), '?', color.White, color.Black)
+	face.DrawRune(got, image.Pt(0, 3), 'A', color.Black, color.White)
+	if counted.draws != 8 || zero.Len() != 3 {
+		t.Errorf("zero cache: drew %d glyphs to cache %d", counted.draws, zero.Len())
+	}
+}
@@ -0,0 +1,166 @@
+package font
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	psf1Magic = 0x0436
+	psf2Magic = 0x864AB572
+
+	psf1Mode512    = 0x01
+	psf1ModeHasTab = 0x02
+	psf1ModeSeq    = 0x04
+
+	psf2HasUnicodeTable = 0x01
+)
+
+// ParsePSF reads a PC Screen Font, version 1 or 2, as used for the Linux
+// console. If the font has a unicode table, glyphs are mapped by it; otherwise
+// glyph i is rune i. Sequences of combining characters in the table are ignored.
+//
+// PSF records no baseline, so the glyphs sit entirely above it: Ascent is the
+// glyph height and Descent is zero. Fallback is '?'.
+func ParsePSF(r io.Reader) (*Font, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(data) >= 4 && binary.LittleEndian.Uint32(data) == psf2Magic:
+		return parsePSF2(data)
+	case len(data) >= 2 && binary.LittleEndian.Uint16(data) == psf1Magic:
+		return parsePSF1(data)
+	}
+	return nil, fmt.Errorf("font: not a PSF file")
+}
+
+func parsePSF1(data []byte) (*Font, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("font: psf1: short header")
+	}
+	mode, height := data[2], int(data[3])
+	count := 256
+	if mode&psf1Mode512 != 0 {
+		count = 512
+	}
+
+	glyphs := data[4:]
+	if len(glyphs) < count*height {
+		return nil, fmt.Errorf("font: psf1: truncated glyph data")
+	}
+	list := psfGlyphs(glyphs, count, 8, height, height)
+	f := newPSFFont(height)
+
+	if mode&(psf1ModeHasTab|psf1ModeSeq) == 0 {
+		for i, glyph := range list {
+			f.Glyphs[rune(i)] = glyph
+		}
+	} else {
+		table := glyphs[count*height:]
+		for _, glyph := range list {
+			if len(table) < 2 {
+				break
+			}
+			inSequence := false
+			for len(table) >= 2 {
+				u := binary.LittleEndian.Uint16(table)
+				table = table[2:]
+				if u == 0xFFFF {
+					break
+				}
+				if u == 0xFFFE {
+					inSequence = true
+				}
+				if !inSequence {
+					f.Glyphs[rune(u)] = glyph
+				}
+			}
+		}
+	}
+
+	return f, nil
+}
+
+func parsePSF2(data []byte) (*Font, error) {
+	if len(data) < 32 {
+		return nil, fmt.Errorf("font: psf2: short header")
+	}
+	var header struct {
+		Magic, Version, HeaderSize, Flags uint32
+		Length, CharSize, Height, Width   uint32
+	}
+	binary.Read(bytes.NewReader(data), binary.LittleEndian, &header)
+
+	count, size := int(header.Length), int(header.CharSize)
+	width, height := int(header.Width), int(header.Height)
+	if int(header.HeaderSize) > len(data) || width <= 0 || height <= 0 || size < (width+7)/8*height {
+		return nil, fmt.Errorf("font: psf2: bad header")
+	}
+	glyphs := data[header.HeaderSize:]
+	if len(glyphs)/size < count {
+		return nil, fmt.Errorf("font: psf2: truncated glyph data")
+	}
+	list := psfGlyphs(glyphs, count, width, height, size)
+	f := newPSFFont(height)
+
+	if header.Flags&psf2HasUnicodeTable == 0 {
+		for i, glyph := range list {
+			f.Glyphs[rune(i)] = glyph
+		}
+	} else {
+		table := glyphs[count*size:]
+		for _, glyph := range list {
+			if len(table) == 0 {
+				break
+			}
+			entry := table
+			if end := bytes.IndexByte(table, 0xFF); end >= 0 {
+				entry, table = table[:end], table[end+1:]
+			} else {
+				table = nil
+			}
+			// anything after 0xFE is a sequence
+			if seq := bytes.IndexByte(entry, 0xFE); seq >= 0 {
+				entry = entry[:seq]
+			}
+			for len(entry) > 0 {
+				r, n := utf8.DecodeRune(entry)
+				f.Glyphs[r] = glyph
+				entry = entry[n:]
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// newPSFFont returns an empty font for glyphs of the given height.
+func newPSFFont(height int) *Font {
+	return &Font{
+		Glyphs:   make(map[rune]*Glyph),
+		Ascent:   height,
+		Fallback: '?',
+	}
+}
+
+// psfGlyphs splits glyphs into count glyphs, each size bytes long.
+func psfGlyphs(glyphs []byte, count, width, height, size int) []*Glyph {
+	stride := (width + 7) / 8
+	list := make([]*Glyph, count)
+	for i := range list {
+		list[i] = &Glyph{
+			Bitmap:  glyphs[i*size : i*size+stride*height],
+			Stride:  stride,
+			Rect:    image.Rect(0, -height, width, 0),
+			Advance: width,
+		}
+	}
+	return list
+}
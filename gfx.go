@@ -20,7 +20,7 @@ type RegionScroller interface {
 
 // VectorScroller is an interface for scrolling an image based on a vector.
 // If you can efficient implement VectorScroller, you can then implement
-// RegionScroller and Scroller via VectorScroller.
+// RegionScroller and Scroller via VectorScroller; VectorScrollAdapter does just that.
 type VectorScroller interface {
 	// VectorScroll scrolls an image by vector.X pixels in x, with postive
 	// values shifting the view port to the right, or the image to left, depending
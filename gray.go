@@ -0,0 +1,460 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// Gray2 is a 4 level gray, 0 being black and 3 white.
+type Gray2 uint8
+
+// Gray4 is a 16 level gray, 0 being black and 15 white.
+type Gray4 uint8
+
+var (
+	Gray2Model = color.ModelFunc(gray2ModelFunc)
+	Gray4Model = color.ModelFunc(gray4ModelFunc)
+)
+
+func gray2ModelFunc(c color.Color) color.Color {
+	if g, ok := c.(Gray2); ok {
+		return g
+	}
+	return Gray2(grayLevel(c, 3))
+}
+
+func gray4ModelFunc(c color.Color) color.Color {
+	if g, ok := c.(Gray4); ok {
+		return g
+	}
+	return Gray4(grayLevel(c, 15))
+}
+
+// grayLevel returns the luminance of c rounded to the nearest of levels+1 steps.
+func grayLevel(c color.Color, levels uint32) uint8 {
+	y := uint32(color.Gray16Model.Convert(c).(color.Gray16).Y)
+	return uint8((y*levels + 0xFFFF/2) / 0xFFFF)
+}
+
+// NewGray2 returns the 4 level gray closest to y, which is an 8 bit luminance.
+func NewGray2(y uint8) Gray2 {
+	return Gray2((uint32(y)*3 + 0xFF/2) / 0xFF)
+}
+
+// NewGray4 returns the 16 level gray closest to y, which is an 8 bit luminance.
+func NewGray4(y uint8) Gray4 {
+	return Gray4((uint32(y)*15 + 0xFF/2) / 0xFF)
+}
+
+func (c Gray2) BitsPerPixel() int {
+	return 2
+}
+
+// Convert makes Gray2 implement color.Model
+func (c Gray2) Convert(in color.Color) color.Color {
+	return gray2ModelFunc(in)
+}
+
+func (c Gray2) RGBA() (r, g, b, a uint32) {
+	// 0xFFFF / 3 == 0x5555, so this is exact
+	y := uint32(c&3) * 0x5555
+	return y, y, y, 0xFFFF
+}
+
+func (c Gray4) BitsPerPixel() int {
+	return 4
+}
+
+// Convert makes Gray4 implement color.Model
+func (c Gray4) Convert(in color.Color) color.Color {
+	return gray4ModelFunc(in)
+}
+
+func (c Gray4) RGBA() (r, g, b, a uint32) {
+	// 0xFFFF / 15 == 0x1111, so this is exact
+	y := uint32(c&15) * 0x1111
+	return y, y, y, 0xFFFF
+}
+
+// PackedGray is a grayscale framebuffer packing several pixels into each byte, as
+// used by e-paper panels with 4 or 16 gray levels. Pixels are stored left to right
+// starting from the most significant bits of each byte, and each row starts on a
+// new byte.
+//
+// Depending on Depth, At returns either Gray2 or Gray4.
+type PackedGray struct {
+	// Pix holds the packed pixels. The pixel at (x, y) is held in the byte
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*Depth/8].
+	Pix []uint8
+	// Stride is the Pix stride (in bytes) between vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+	// Depth is how many bits make up a pixel; either 2 or 4.
+	Depth int
+	// ScrollFill decides what happens to the area vacated by scrolling.
+	ScrollFill ScrollFill
+
+	doubleBuf *PackedGray
+	dirty     Region
+}
+
+// NewPackedGray returns a new PackedGray framebuffer with the given bounds.
+// depth must be either 2 or 4.
+func NewPackedGray(r image.Rectangle, depth int) *PackedGray {
+	if depth != 2 && depth != 4 {
+		panic("gfx.NewPackedGray: depth must be 2 or 4")
+	}
+	stride := (r.Dx()*depth + 7) / 8
+	return &PackedGray{
+		Pix:    make([]uint8, stride*r.Dy()),
+		Stride: stride,
+		Rect:   r,
+		Depth:  depth,
+	}
+}
+
+// NewPackedGrayWithDoubleBuffer returns a new PackedGray framebuffer, with the same
+// depth as base, that buffers all changes; they are copied into base when Flush is called.
+func NewPackedGrayWithDoubleBuffer(base *PackedGray) *PackedGray {
+	buf := NewPackedGray(base.Rect, base.Depth)
+	buf.Blit(base, base.Rect.Min)
+	buf.doubleBuf = base
+	return buf
+}
+
+func (p *PackedGray) ColorModel() color.Model {
+	if p.Depth == 2 {
+		return Gray2Model
+	}
+	return Gray4Model
+}
+
+func (p *PackedGray) Bounds() image.Rectangle { return p.Rect }
+
+func (p *PackedGray) At(x, y int) color.Color {
+	if p.Depth == 2 {
+		return Gray2(p.LevelAt(x, y))
+	}
+	return Gray4(p.LevelAt(x, y))
+}
+
+// LevelAt returns the raw gray level of the pixel at (x, y).
+func (p *PackedGray) LevelAt(x, y int) uint8 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return 0
+	}
+	i, shift := p.levelOffset(x, y)
+	return p.Pix[i] >> shift & p.levelMask()
+}
+
+func (p *PackedGray) Set(x, y int, c color.Color) {
+	p.SetLevel(x, y, p.level(c))
+}
+
+// SetLevel sets the raw gray level of the pixel at (x, y). Bits of level beyond
+// Depth are ignored.
+func (p *PackedGray) SetLevel(x, y int, level uint8) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	p.setLevel(x, y, level)
+}
+
+func (p *PackedGray) setLevel(x, y int, level uint8) {
+	i, shift := p.levelOffset(x, y)
+	mask := p.levelMask() << shift
+	p.Pix[i] = p.Pix[i]&^mask | level<<shift&mask
+}
+
+// level converts c into a raw gray level.
+func (p *PackedGray) level(c color.Color) uint8 {
+	if p.Depth == 2 {
+		return uint8(gray2ModelFunc(c).(Gray2))
+	}
+	return uint8(gray4ModelFunc(c).(Gray4))
+}
+
+// levelOffset returns the index into Pix and the shift of the pixel at (x, y).
+func (p *PackedGray) levelOffset(x, y int) (int, uint) {
+	x -= p.Rect.Min.X
+	y -= p.Rect.Min.Y
+	perByte := p.perByte()
+	return y*p.Stride + x/perByte, uint(8 - p.Depth*(x%perByte+1))
+}
+
+func (p *PackedGray) levelMask() uint8 {
+	return 1<<p.Depth - 1
+}
+
+// perByte returns how many pixels are packed in to a byte.
+func (p *PackedGray) perByte() int {
+	return 8 / p.Depth
+}
+
+// spanMask returns the mask covering pixels [from, to) of a byte.
+func (p *PackedGray) spanMask(from, to int) uint8 {
+	return spanMask(from*p.Depth, to*p.Depth, true)
+}
+
+// aligned reports whether x falls on the first pixel of a byte.
+func (p *PackedGray) aligned(x int) bool {
+	return (x-p.Rect.Min.X)%p.perByte() == 0
+}
+
+// Fill implements gfx.Filler. Whole bytes are written wherever where covers them.
+func (p *PackedGray) Fill(where image.Rectangle, c color.Color) {
+	where = p.Rect.Intersect(where)
+	if where.Empty() {
+		return
+	}
+	p.dirtyAdd(where)
+
+	// replicate the level across a whole byte
+	level := p.level(c)
+	pattern := level
+	for i := p.Depth; i < 8; i += p.Depth {
+		pattern = pattern<<p.Depth | level
+	}
+
+	perByte := p.perByte()
+	r := where.Sub(p.Rect.Min)
+	first, last := r.Min.X/perByte, (r.Max.X-1)/perByte
+	headMask := p.spanMask(r.Min.X-first*perByte, min(r.Max.X-first*perByte, perByte))
+	tailMask := p.spanMask(0, r.Max.X-last*perByte)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := p.Pix[y*p.Stride : y*p.Stride+last+1]
+		row[first] = row[first]&^headMask | pattern&headMask
+		if first == last {
+			continue
+		}
+		row[last] = row[last]&^tailMask | pattern&tailMask
+		fillRow(row[first+1:last], []byte{pattern})
+	}
+}
+
+// Blit implements gfx.Blitter. Blitting from another *PackedGray of the same Depth
+// copies whole bytes when the two are aligned on byte boundaries.
+func (p *PackedGray) Blit(src image.Image, where image.Point) {
+	destRect, sp := clipBlit(p.Rect, src.Bounds(), where)
+	if destRect.Empty() {
+		return
+	}
+	p.dirtyAdd(destRect)
+
+	s, ok := src.(*PackedGray)
+	if !ok {
+		for y := 0; y < destRect.Dy(); y++ {
+			for x := 0; x < destRect.Dx(); x++ {
+				p.setLevel(destRect.Min.X+x, destRect.Min.Y+y, p.level(src.At(sp.X+x, sp.Y+y)))
+			}
+		}
+		return
+	}
+
+	if s.Depth == p.Depth && p.aligned(destRect.Min.X) && s.aligned(sp.X) {
+		p.blitRows(s, destRect, sp)
+		return
+	}
+
+	p.blitLevels(s, destRect, sp)
+}
+
+// blitRows copies byte aligned rows. Only the last byte of each row may be partial.
+func (p *PackedGray) blitRows(s *PackedGray, destRect image.Rectangle, sp image.Point) {
+	perByte := p.perByte()
+	full, rem := destRect.Dx()/perByte, destRect.Dx()%perByte
+	mask := p.spanMask(0, rem)
+	copyRow := func(y int) {
+		d, _ := p.levelOffset(destRect.Min.X, destRect.Min.Y+y)
+		o, _ := s.levelOffset(sp.X, sp.Y+y)
+		// read the partial byte before the copy can overwrite it
+		var last byte
+		if rem != 0 {
+			last = s.Pix[o+full]
+		}
+		copy(p.Pix[d:d+full], s.Pix[o:o+full])
+		if rem != 0 {
+			p.Pix[d+full] = p.Pix[d+full]&^mask | last&mask
+		}
+	}
+
+	if samePix(s.Pix, p.Pix) && sp.Y < destRect.Min.Y {
+		// overlapping copy within ourselves; go bottom up
+		for y := destRect.Dy() - 1; y >= 0; y-- {
+			copyRow(y)
+		}
+		return
+	}
+	for y := 0; y < destRect.Dy(); y++ {
+		copyRow(y)
+	}
+}
+
+// blitLevels copies pixels one at a time, rescaling levels if the depths differ,
+// and going the right way round should p and s share their pixels.
+func (p *PackedGray) blitLevels(s *PackedGray, destRect image.Rectangle, sp image.Point) {
+	overlap := samePix(s.Pix, p.Pix)
+	yStart, yEnd, yStep := 0, destRect.Dy(), 1
+	if overlap && sp.Y < destRect.Min.Y {
+		yStart, yEnd, yStep = destRect.Dy()-1, -1, -1
+	}
+	xStart, xEnd, xStep := 0, destRect.Dx(), 1
+	if overlap && sp.X < destRect.Min.X {
+		xStart, xEnd, xStep = destRect.Dx()-1, -1, -1
+	}
+	for y := yStart; y != yEnd; y += yStep {
+		for x := xStart; x != xEnd; x += xStep {
+			level := s.LevelAt(sp.X+x, sp.Y+y)
+			if s.Depth != p.Depth {
+				level = uint8(uint(level) * uint(p.levelMask()) / uint(s.levelMask()))
+			}
+			p.setLevel(destRect.Min.X+x, destRect.Min.Y+y, level)
+		}
+	}
+}
+
+// SubImage returns an image representing the portion of p visible through r. The
+// returned value shares pixels with the original when r is aligned to p's bytes;
+// otherwise it is a copy. Either way it does not share p's double buffer.
+func (p *PackedGray) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	if r.Empty() {
+		return &PackedGray{Depth: p.Depth}
+	}
+
+	if p.aligned(r.Min.X) {
+		i, _ := p.levelOffset(r.Min.X, r.Min.Y)
+		return &PackedGray{
+			Pix:    p.Pix[i:],
+			Stride: p.Stride,
+			Rect:   r,
+			Depth:  p.Depth,
+		}
+	}
+
+	sub := NewPackedGray(r, p.Depth)
+	sub.blitLevels(p, r, r.Min)
+	return sub
+}
+
+// wholeBytes reports whether region covers whole bytes of every row it spans. The
+// padding at the end of a row counts as part of the last pixel.
+func (p *PackedGray) wholeBytes(region image.Rectangle) bool {
+	return p.aligned(region.Min.X) && (p.aligned(region.Max.X) || region.Max.X == p.Rect.Max.X)
+}
+
+// Scroll implements gfx.Scroller.
+func (p *PackedGray) Scroll(amount int) {
+	p.RegionScroll(p.Rect, amount)
+}
+
+// RegionScroll implements gfx.RegionScroller. Unless ScrollFill says otherwise,
+// the vacated area is left as it was.
+func (p *PackedGray) RegionScroll(region image.Rectangle, amount int) {
+	region = p.Rect.Intersect(region)
+	if region.Empty() || amount == 0 {
+		return
+	}
+	p.dirtyAdd(region)
+	defer p.ScrollFill.apply(p, region, image.Pt(0, amount))
+
+	if amount >= region.Dy() || -amount >= region.Dy() {
+		return
+	}
+
+	if p.wholeBytes(region) {
+		start, _ := p.levelOffset(region.Min.X, region.Min.Y)
+		scrollRows(p.Pix, p.Stride, start, (region.Dx()+p.perByte()-1)/p.perByte(), region.Dy(), amount)
+		return
+	}
+
+	src := region.Add(image.Pt(0, amount)).Intersect(region)
+	p.blitLevels(p, src.Sub(image.Pt(0, amount)), src.Min)
+}
+
+// VectorScroll implements gfx.VectorScroller. Unless ScrollFill says otherwise,
+// pixels scrolled off one edge of region wrap around to the opposite edge.
+func (p *PackedGray) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = p.Rect.Intersect(region)
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	p.dirtyAdd(region)
+	defer p.ScrollFill.apply(p, region, vector)
+
+	perByte := p.perByte()
+	if p.wholeBytes(region) && region.Dx()%perByte == 0 && vector.X%perByte == 0 {
+		start, _ := p.levelOffset(region.Min.X, region.Min.Y)
+		rotateRows(p.Pix, p.Stride, start, region.Dx()/perByte, region.Dy(), 1, image.Pt(vector.X/perByte, vector.Y))
+		return
+	}
+
+	// otherwise rotate by reversing, one pixel at a time
+	if k := mod(vector.X, region.Dx()); k != 0 {
+		for y := region.Min.Y; y < region.Max.Y; y++ {
+			p.reverseLevels(region.Min.X, region.Min.X+k, y, 1, 0)
+			p.reverseLevels(region.Min.X+k, region.Max.X, y, 1, 0)
+			p.reverseLevels(region.Min.X, region.Max.X, y, 1, 0)
+		}
+	}
+	if k := mod(vector.Y, region.Dy()); k != 0 {
+		start, _ := p.levelOffset(region.Min.X, region.Min.Y)
+		if p.wholeBytes(region) {
+			// whole rows can still be swapped a byte at a time
+			width := (region.Dx() + perByte - 1) / perByte
+			rotateRows(p.Pix, p.Stride, start, width, region.Dy(), 1, image.Pt(0, k))
+			return
+		}
+		for x := region.Min.X; x < region.Max.X; x++ {
+			p.reverseLevels(region.Min.Y, region.Min.Y+k, x, 0, 1)
+			p.reverseLevels(region.Min.Y+k, region.Max.Y, x, 0, 1)
+			p.reverseLevels(region.Min.Y, region.Max.Y, x, 0, 1)
+		}
+	}
+}
+
+// reverseLevels reverses the pixels [from, to) along a row (dx == 1) or a column (dy == 1).
+func (p *PackedGray) reverseLevels(from, to, at, dx, dy int) {
+	for i, j := from, to-1; i < j; i, j = i+1, j-1 {
+		xi, yi := at*dy+i*dx, at*dx+i*dy
+		xj, yj := at*dy+j*dx, at*dx+j*dy
+		a, b := p.LevelAt(xi, yi), p.LevelAt(xj, yj)
+		p.setLevel(xi, yi, b)
+		p.setLevel(xj, yj, a)
+	}
+}
+
+// Flush implements gfx.DoubleBufferer. If p was not created with
+// NewPackedGrayWithDoubleBuffer, Flush does nothing.
+func (p *PackedGray) Flush() {
+	if p.doubleBuf == nil {
+		return
+	}
+
+	if p.dirty.Eq(p.Rect) && p.Stride == p.doubleBuf.Stride {
+		copy(p.doubleBuf.Pix, p.Pix)
+	} else {
+		for _, r := range p.dirty.Rects() {
+			// widen each dirty rectangle to whole bytes, so Blit can copy them directly
+			r.Min.X -= (r.Min.X - p.Rect.Min.X) % p.perByte()
+			p.doubleBuf.Blit(p.SubImage(r), r.Min)
+		}
+	}
+
+	p.dirty.Clear()
+}
+
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+// Changes are only tracked if p has a double buffer.
+func (p *PackedGray) DirtyRects() []image.Rectangle {
+	return p.dirty.Rects()
+}
+
+func (p *PackedGray) dirtyAdd(rect image.Rectangle) {
+	if p.doubleBuf == nil {
+		return
+	}
+	p.dirty.Add(rect.Intersect(p.Rect))
+}
@@ -0,0 +1,141 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Blitter        = &PackedGray{}
+	_ Filler         = &PackedGray{}
+	_ VectorScroller = &PackedGray{}
+	_ DoubleBufferer = &PackedGray{}
+)
+
+func Test_GrayRoundTrip(t *testing.T) {
+	for _, depth := range []int{2, 4} {
+		p := NewPackedGray(image.Rect(0, 0, 7, 1), depth)
+		levels := 1 << depth
+		for l := 0; l < levels; l++ {
+			x := l % 7
+			c := p.ColorModel().Convert(color.Gray16{uint16(l * 0xFFFF / (levels - 1))})
+			p.Set(x, 0, c)
+			if got := p.At(x, 0); got != c {
+				t.Errorf("depth %d: set %v, got %v back", depth, c, got)
+			}
+			if got := p.LevelAt(x, 0); int(got) != l {
+				t.Errorf("depth %d: level %d stored as %d", depth, l, got)
+			}
+			// and through RGBA
+			if got := p.ColorModel().Convert(p.At(x, 0)); got != c {
+				t.Errorf("depth %d: %v did not survive RGBA, got %v", depth, c, got)
+			}
+		}
+	}
+
+	p := NewPackedGray(image.Rect(0, 0, 4, 1), 2)
+	p.Set(1, 0, Gray2(3))
+	if p.Pix[0] != 0x30 {
+		t.Errorf("got byte %08b, want 00110000", p.Pix[0])
+	}
+}
+
+// Test_GrayFastPaths checks the byte-wise paths against setting pixels one at a time.
+func Test_GrayFastPaths(t *testing.T) {
+	rand.Seed(2)
+	for _, depth := range []int{2, 4} {
+		for i := 0; i < 200; i++ {
+			bounds := image.Rect(0, 0, 1+rand.Intn(40), 1+rand.Intn(20))
+			fast, slow := NewPackedGray(bounds, depth), NewPackedGray(bounds, depth)
+			randomLevels(fast, slow)
+
+			r := randomRect(bounds)
+			switch rand.Intn(5) {
+			case 0:
+				level := uint8(rand.Intn(1 << depth))
+				fast.Fill(r, fast.ColorModel().Convert(color.Gray16{uint16(uint(level) * 0xFFFF / uint(fast.levelMask()))}))
+				forAllPix(r.Intersect(bounds), func(x, y int) { slow.setLevel(x, y, level) })
+			case 1:
+				src := NewPackedGray(bounds, depth)
+				randomLevels(src, src)
+				at := image.Pt(rand.Intn(8)*4, rand.Intn(8))
+				sub := src.SubImage(r)
+				fast.Blit(sub, at)
+				forAllPix(sub.Bounds(), func(x, y int) {
+					slow.SetLevel(x-sub.Bounds().Min.X+at.X, y-sub.Bounds().Min.Y+at.Y, src.LevelAt(x, y))
+				})
+			case 2:
+				amount := rand.Intn(2*bounds.Dy()+1) - bounds.Dy()
+				r = bounds
+				if rand.Intn(2) == 0 {
+					r.Min.X = rand.Intn(bounds.Dx())
+				}
+				fast.RegionScroll(r, amount)
+				ref := NewPackedGray(bounds, depth)
+				ref.Blit(slow, bounds.Min)
+				forAllPix(r, func(x, y int) {
+					if sy := y + amount; sy >= r.Min.Y && sy < r.Max.Y {
+						slow.setLevel(x, y, ref.LevelAt(x, sy))
+					}
+				})
+			case 3:
+				v := image.Pt(rand.Intn(81)-40, rand.Intn(41)-20)
+				if rand.Intn(2) == 0 {
+					v.X = 0
+				}
+				r = bounds
+				fast.VectorScroll(r, v)
+				ref := NewPackedGray(bounds, depth)
+				ref.Blit(slow, bounds.Min)
+				forAllPix(r, func(x, y int) {
+					slow.setLevel(x, y, ref.LevelAt(mod(x+v.X, r.Dx()), mod(y+v.Y, r.Dy())))
+				})
+			case 4:
+				// from a SubImage sharing its pixels, overlapping, byte aligned or not
+				perByte := fast.perByte()
+				r.Min.X -= r.Min.X % perByte
+				at := image.Pt(rand.Intn(bounds.Dx()), rand.Intn(bounds.Dy()))
+				if rand.Intn(2) == 0 {
+					at.X -= at.X % perByte
+				}
+				sub := fast.SubImage(r)
+				ref := NewPackedGray(bounds, depth)
+				ref.Blit(slow, bounds.Min)
+				fast.Blit(sub, at)
+				forAllPix(sub.Bounds(), func(x, y int) {
+					slow.SetLevel(x-sub.Bounds().Min.X+at.X, y-sub.Bounds().Min.Y+at.Y, ref.LevelAt(x, y))
+				})
+			}
+
+			forAllPix(bounds, func(x, y int) {
+				if fast.LevelAt(x, y) != slow.LevelAt(x, y) {
+					t.Fatalf("depth %d, iteration %d: pixel (%d,%d) differs", depth, i, x, y)
+				}
+			})
+		}
+	}
+}
+
+func Test_GrayDoubleBuffer(t *testing.T) {
+	front := NewPackedGray(image.Rect(0, 0, 30, 10), 4)
+	back := NewPackedGrayWithDoubleBuffer(front)
+	back.Fill(image.Rect(3, 3, 9, 5), color.White)
+	if front.LevelAt(3, 3) != 0 {
+		t.Fatal("front buffer changed before Flush")
+	}
+	back.Flush()
+	if front.LevelAt(3, 3) != 15 || front.LevelAt(8, 4) != 15 || front.LevelAt(9, 4) != 0 {
+		t.Fatal("Flush did not copy the dirty area")
+	}
+}
+
+func randomLevels(a, b *PackedGray) {
+	forAllPix(a.Rect, func(x, y int) {
+		level := uint8(rand.Intn(1 << a.Depth))
+		a.setLevel(x, y, level)
+		b.setLevel(x, y, level)
+	})
+}
@@ -0,0 +1,580 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// MonoLayout describes how the pixels of a Mono framebuffer are packed into bytes.
+type MonoLayout int
+
+const (
+	// MonoVertical packs 8 vertically adjacent pixels into each byte, least significant
+	// bit on top. Rows of bytes are called pages. This is what SSD1306, SH1106 and
+	// most other small OLED controllers expect.
+	MonoVertical MonoLayout = iota
+	// MonoHorizontal packs 8 horizontally adjacent pixels into each byte, most
+	// significant bit on the left, as used by Sharp memory LCDs and most e-paper.
+	MonoHorizontal
+)
+
+var (
+	monoOff = color.Gray{0}
+	monoOn  = color.Gray{0xFF}
+)
+
+// MonoModel converts colors to on or off pixels by comparing their luminance to a
+// threshold. The zero value uses ITU-R BT.601 luma and a threshold of half brightness.
+type MonoModel struct {
+	// Threshold is the 16-bit luminance at or above which a pixel is on. A zero
+	// Threshold means 0x8000.
+	Threshold uint32
+	// Luminance computes the 16-bit brightness of a color. If nil, the Y of
+	// color.Gray16Model is used.
+	Luminance func(color.Color) uint32
+}
+
+// Convert implements color.Model. It returns either black or white color.Gray.
+func (m MonoModel) Convert(c color.Color) color.Color {
+	if m.On(c) {
+		return monoOn
+	}
+	return monoOff
+}
+
+// On reports whether c should be displayed as a lit pixel.
+func (m MonoModel) On(c color.Color) bool {
+	threshold := m.Threshold
+	if threshold == 0 {
+		threshold = 0x8000
+	}
+
+	if m.Luminance != nil {
+		return m.Luminance(c) >= threshold
+	}
+
+	switch c := c.(type) {
+	case color.Gray:
+		// fast path for what we hand out ourselves
+		return uint32(c.Y)*0x101 >= threshold
+	case color.Gray16:
+		return uint32(c.Y) >= threshold
+	}
+	return uint32(color.Gray16Model.Convert(c).(color.Gray16).Y) >= threshold
+}
+
+// Mono is a 1 bit-per-pixel framebuffer. Pixels are packed according to Layout,
+// and Pix can be sent as-is to a display controller expecting that layout.
+//
+// Colors are mapped to on or off by Model, so any color.Color can be drawn.
+// At returns white for lit pixels and black for unlit pixels.
+type Mono struct {
+	// Pix holds the packed pixels. For MonoVertical, the pixel at (x, y) is bit
+	// (y-Rect.Min.Y)%8 of Pix[((y-Rect.Min.Y)/8)*Stride + x-Rect.Min.X]. For
+	// MonoHorizontal, it is bit 7-(x-Rect.Min.X)%8 of
+	// Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)/8].
+	Pix []uint8
+	// Stride is the Pix stride (in bytes) between vertically adjacent pages
+	// (MonoVertical) or rows (MonoHorizontal).
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+	// Layout is how pixels are packed into Pix.
+	Layout MonoLayout
+	// Model decides which colors turn a pixel on.
+	Model MonoModel
+	// ScrollFill decides what happens to the area vacated by scrolling.
+	ScrollFill ScrollFill
+
+	doubleBuf *Mono
+	dirty     Region
+}
+
+// NewMono returns a new Mono framebuffer with the given bounds and layout.
+func NewMono(r image.Rectangle, layout MonoLayout) *Mono {
+	var stride, rows int
+	switch layout {
+	case MonoVertical:
+		stride, rows = r.Dx(), (r.Dy()+7)/8
+	default:
+		stride, rows = (r.Dx()+7)/8, r.Dy()
+	}
+	return &Mono{
+		Pix:    make([]uint8, stride*rows),
+		Stride: stride,
+		Rect:   r,
+		Layout: layout,
+	}
+}
+
+// NewMonoWithDoubleBuffer returns a new Mono framebuffer, with the same layout as
+// base, that buffers all changes; they are copied into base when Flush is called.
+func NewMonoWithDoubleBuffer(base *Mono) *Mono {
+	buf := NewMono(base.Rect, base.Layout)
+	buf.Model = base.Model
+	buf.Blit(base, base.Rect.Min)
+	buf.doubleBuf = base
+	return buf
+}
+
+func (m *Mono) ColorModel() color.Model { return m.Model }
+
+func (m *Mono) Bounds() image.Rectangle { return m.Rect }
+
+func (m *Mono) At(x, y int) color.Color {
+	if m.BitAt(x, y) {
+		return monoOn
+	}
+	return monoOff
+}
+
+// BitAt reports whether the pixel at (x, y) is lit.
+func (m *Mono) BitAt(x, y int) bool {
+	if !(image.Point{x, y}.In(m.Rect)) {
+		return false
+	}
+	i, mask := m.bitOffset(x, y)
+	return m.Pix[i]&mask != 0
+}
+
+func (m *Mono) Set(x, y int, c color.Color) {
+	m.SetBit(x, y, m.Model.On(c))
+}
+
+// SetBit lights (on is true) or clears the pixel at (x, y).
+func (m *Mono) SetBit(x, y int, on bool) {
+	if !(image.Point{x, y}.In(m.Rect)) {
+		return
+	}
+	m.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	m.setBit(x, y, on)
+}
+
+func (m *Mono) setBit(x, y int, on bool) {
+	i, mask := m.bitOffset(x, y)
+	if on {
+		m.Pix[i] |= mask
+	} else {
+		m.Pix[i] &^= mask
+	}
+}
+
+// bitOffset returns the index into Pix and the bit mask for the pixel at (x, y).
+func (m *Mono) bitOffset(x, y int) (int, uint8) {
+	x -= m.Rect.Min.X
+	y -= m.Rect.Min.Y
+	if m.Layout == MonoVertical {
+		return (y/8)*m.Stride + x, 1 << (y % 8)
+	}
+	return y*m.Stride + x/8, 0x80 >> (x % 8)
+}
+
+// Fill implements gfx.Filler. Whole bytes are written wherever where covers them.
+func (m *Mono) Fill(where image.Rectangle, c color.Color) {
+	where = m.Rect.Intersect(where)
+	if where.Empty() {
+		return
+	}
+	m.dirtyAdd(where)
+	m.fill(where, m.Model.On(c))
+}
+
+func (m *Mono) fill(where image.Rectangle, on bool) {
+	r := where.Sub(m.Rect.Min)
+
+	if m.Layout == MonoVertical {
+		for page := r.Min.Y / 8; page <= (r.Max.Y-1)/8; page++ {
+			mask := spanMask(max(r.Min.Y-page*8, 0), min(r.Max.Y-page*8, 8), false)
+			row := m.Pix[page*m.Stride+r.Min.X : page*m.Stride+r.Max.X]
+			if mask == 0xFF {
+				if on {
+					fillRow(row, []byte{0xFF})
+				} else {
+					clear(row)
+				}
+				continue
+			}
+			for i := range row {
+				if on {
+					row[i] |= mask
+				} else {
+					row[i] &^= mask
+				}
+			}
+		}
+		return
+	}
+
+	first, last := r.Min.X/8, (r.Max.X-1)/8
+	headMask := spanMask(r.Min.X-first*8, min(r.Max.X-first*8, 8), true)
+	tailMask := spanMask(0, r.Max.X-last*8, true)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := m.Pix[y*m.Stride : y*m.Stride+last+1]
+		if first == last {
+			setMasked(&row[first], headMask, on)
+			continue
+		}
+		setMasked(&row[first], headMask, on)
+		setMasked(&row[last], tailMask, on)
+		if on {
+			fillRow(row[first+1:last], []byte{0xFF})
+		} else {
+			clear(row[first+1 : last])
+		}
+	}
+}
+
+// Blit implements gfx.Blitter. Blitting from another *Mono of the same layout
+// copies whole bytes when the two are aligned on byte boundaries; anything else
+// is converted through Model one pixel at a time.
+func (m *Mono) Blit(src image.Image, where image.Point) {
+	destRect, sp := clipBlit(m.Rect, src.Bounds(), where)
+	if destRect.Empty() {
+		return
+	}
+	m.dirtyAdd(destRect)
+
+	s, ok := src.(*Mono)
+	if !ok {
+		for y := 0; y < destRect.Dy(); y++ {
+			for x := 0; x < destRect.Dx(); x++ {
+				m.setBit(destRect.Min.X+x, destRect.Min.Y+y, m.Model.On(src.At(sp.X+x, sp.Y+y)))
+			}
+		}
+		return
+	}
+
+	// byte-wise copies
+	if s.Layout == m.Layout {
+		switch m.Layout {
+		case MonoVertical:
+			if (destRect.Min.Y-m.Rect.Min.Y)%8 == 0 && (sp.Y-s.Rect.Min.Y)%8 == 0 {
+				m.blitPages(s, destRect, sp)
+				return
+			}
+		case MonoHorizontal:
+			if (destRect.Min.X-m.Rect.Min.X)%8 == 0 && (sp.X-s.Rect.Min.X)%8 == 0 {
+				m.blitRows(s, destRect, sp)
+				return
+			}
+		}
+	}
+
+	m.blitBits(s, destRect, sp)
+}
+
+// blitPages copies page aligned data between vertically packed framebuffers.
+// Only the last page may be partial.
+func (m *Mono) blitPages(s *Mono, destRect image.Rectangle, sp image.Point) {
+	width := destRect.Dx()
+	overlap := samePix(s.Pix, m.Pix)
+	copyPage := func(y int) {
+		d, _ := m.bitOffset(destRect.Min.X, destRect.Min.Y+y)
+		o, _ := s.bitOffset(sp.X, sp.Y+y)
+		if rows := destRect.Dy() - y; rows < 8 {
+			mask := spanMask(0, rows, false)
+			if overlap && o < d {
+				for x := width - 1; x >= 0; x-- {
+					m.Pix[d+x] = m.Pix[d+x]&^mask | s.Pix[o+x]&mask
+				}
+				return
+			}
+			for x := range width {
+				m.Pix[d+x] = m.Pix[d+x]&^mask | s.Pix[o+x]&mask
+			}
+			return
+		}
+		copy(m.Pix[d:d+width], s.Pix[o:o+width])
+	}
+
+	if overlap && sp.Y < destRect.Min.Y {
+		// overlapping copy within ourselves; go bottom up
+		for y := (destRect.Dy() - 1) / 8 * 8; y >= 0; y -= 8 {
+			copyPage(y)
+		}
+		return
+	}
+	for y := 0; y < destRect.Dy(); y += 8 {
+		copyPage(y)
+	}
+}
+
+// blitRows copies byte aligned data between horizontally packed framebuffers.
+// Only the last byte of each row may be partial.
+func (m *Mono) blitRows(s *Mono, destRect image.Rectangle, sp image.Point) {
+	full, rem := destRect.Dx()/8, destRect.Dx()%8
+	mask := spanMask(0, rem, true)
+	copyRow := func(y int) {
+		d, _ := m.bitOffset(destRect.Min.X, destRect.Min.Y+y)
+		o, _ := s.bitOffset(sp.X, sp.Y+y)
+		// read the partial byte before the copy can overwrite it
+		var last byte
+		if rem != 0 {
+			last = s.Pix[o+full]
+		}
+		copy(m.Pix[d:d+full], s.Pix[o:o+full])
+		if rem != 0 {
+			m.Pix[d+full] = m.Pix[d+full]&^mask | last&mask
+		}
+	}
+
+	if samePix(s.Pix, m.Pix) && sp.Y < destRect.Min.Y {
+		// overlapping copy within ourselves; go bottom up
+		for y := destRect.Dy() - 1; y >= 0; y-- {
+			copyRow(y)
+		}
+		return
+	}
+	for y := 0; y < destRect.Dy(); y++ {
+		copyRow(y)
+	}
+}
+
+// blitBits copies unaligned data bit by bit, going the right way round should m
+// and s be one and the same.
+func (m *Mono) blitBits(s *Mono, destRect image.Rectangle, sp image.Point) {
+	yStart, yEnd, yStep := 0, destRect.Dy(), 1
+	overlap := samePix(s.Pix, m.Pix)
+	if overlap && sp.Y < destRect.Min.Y {
+		yStart, yEnd, yStep = destRect.Dy()-1, -1, -1
+	}
+	xStart, xEnd, xStep := 0, destRect.Dx(), 1
+	if overlap && sp.X < destRect.Min.X {
+		xStart, xEnd, xStep = destRect.Dx()-1, -1, -1
+	}
+	for y := yStart; y != yEnd; y += yStep {
+		for x := xStart; x != xEnd; x += xStep {
+			m.setBit(destRect.Min.X+x, destRect.Min.Y+y, s.BitAt(sp.X+x, sp.Y+y))
+		}
+	}
+}
+
+// SubImage returns an image representing the portion of m visible through r. The
+// returned value shares pixels with the original when r is aligned to m's bytes;
+// otherwise it is a copy. Either way it does not share m's double buffer.
+func (m *Mono) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(m.Rect)
+	if r.Empty() {
+		return &Mono{Layout: m.Layout, Model: m.Model}
+	}
+
+	aligned := (r.Min.Y-m.Rect.Min.Y)%8 == 0
+	if m.Layout == MonoHorizontal {
+		aligned = (r.Min.X-m.Rect.Min.X)%8 == 0
+	}
+	if aligned {
+		i, _ := m.bitOffset(r.Min.X, r.Min.Y)
+		return &Mono{
+			Pix:    m.Pix[i:],
+			Stride: m.Stride,
+			Rect:   r,
+			Layout: m.Layout,
+			Model:  m.Model,
+		}
+	}
+
+	sub := NewMono(r, m.Layout)
+	sub.Model = m.Model
+	sub.blitBits(m, r, r.Min)
+	return sub
+}
+
+// Scroll implements gfx.Scroller.
+func (m *Mono) Scroll(amount int) {
+	m.RegionScroll(m.Rect, amount)
+}
+
+// RegionScroll implements gfx.RegionScroller. Unless ScrollFill says otherwise,
+// the vacated area is left as it was.
+func (m *Mono) RegionScroll(region image.Rectangle, amount int) {
+	region = m.Rect.Intersect(region)
+	if region.Empty() || amount == 0 {
+		return
+	}
+	m.dirtyAdd(region)
+	defer m.ScrollFill.apply(m, region, image.Pt(0, amount))
+
+	if amount >= region.Dy() || -amount >= region.Dy() {
+		return
+	}
+
+	if m.Layout == MonoHorizontal {
+		if (region.Min.X-m.Rect.Min.X)%8 == 0 && (region.Dx()%8 == 0 || region.Max.X == m.Rect.Max.X) {
+			// whole bytes; any padding bits at the end of a row are ours to clobber
+			start, _ := m.bitOffset(region.Min.X, region.Min.Y)
+			scrollRows(m.Pix, m.Stride, start, (region.Dx()+7)/8, region.Dy(), amount)
+			return
+		}
+	} else if region.Min.Y == m.Rect.Min.Y && region.Max.Y == m.Rect.Max.Y {
+		m.scrollPages(region, amount)
+		return
+	}
+
+	src := region.Add(image.Pt(0, amount)).Intersect(region)
+	m.blitBits(m, src.Sub(image.Pt(0, amount)), src.Min)
+}
+
+// scrollPages scrolls full height columns of a vertically packed framebuffer by
+// shifting bits between pages.
+func (m *Mono) scrollPages(region image.Rectangle, amount int) {
+	pages := (m.Rect.Dy() + 7) / 8
+	x0 := region.Min.X - m.Rect.Min.X
+	width := region.Dx()
+
+	pageAt := func(page, x int) uint8 {
+		if page < 0 || page >= pages {
+			return 0
+		}
+		return m.Pix[page*m.Stride+x]
+	}
+
+	if amount > 0 {
+		skip, shift := amount/8, uint(amount%8)
+		for page := 0; page < pages-skip; page++ {
+			for x := x0; x < x0+width; x++ {
+				v := pageAt(page+skip, x) >> shift
+				if shift != 0 {
+					v |= pageAt(page+skip+1, x) << (8 - shift)
+				}
+				m.Pix[page*m.Stride+x] = m.scrollKeep(page, x, amount, v)
+			}
+		}
+		return
+	}
+
+	amount = -amount
+	skip, shift := amount/8, uint(amount%8)
+	for page := pages - 1; page >= skip; page-- {
+		for x := x0; x < x0+width; x++ {
+			v := pageAt(page-skip, x) << shift
+			if shift != 0 {
+				v |= pageAt(page-skip-1, x) >> (8 - shift)
+			}
+			m.Pix[page*m.Stride+x] = m.scrollKeep(page, x, -amount, v)
+		}
+	}
+}
+
+// scrollKeep merges the scrolled byte v into page, keeping the bits that a
+// scroll by amount vacates (and so should be left as they were).
+func (m *Mono) scrollKeep(page, x, amount int, v uint8) uint8 {
+	var keep uint8
+	height := m.Rect.Dy()
+	if amount > 0 {
+		keep = spanMask(max(height-amount-page*8, 0), 8, false)
+	} else {
+		keep = spanMask(0, min(-amount-page*8, 8), false)
+	}
+	if keep == 0 {
+		return v
+	}
+	return v&^keep | m.Pix[page*m.Stride+x]&keep
+}
+
+// VectorScroll implements gfx.VectorScroller. Unless ScrollFill says otherwise,
+// pixels scrolled off one edge of region wrap around to the opposite edge.
+func (m *Mono) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = m.Rect.Intersect(region)
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	m.dirtyAdd(region)
+	defer m.ScrollFill.apply(m, region, vector)
+
+	// page aligned regions of vertically packed framebuffers can be wrapped
+	// horizontally by whole bytes
+	if m.Layout == MonoVertical && vector.Y == 0 &&
+		(region.Min.Y-m.Rect.Min.Y)%8 == 0 && (region.Dy()%8 == 0 || region.Max.Y == m.Rect.Max.Y) {
+		start, _ := m.bitOffset(region.Min.X, region.Min.Y)
+		rotateRows(m.Pix, m.Stride, start, region.Dx(), (region.Dy()+7)/8, 1, vector)
+		return
+	}
+
+	// otherwise rotate by reversing, one bit at a time
+	if k := mod(vector.X, region.Dx()); k != 0 {
+		for y := region.Min.Y; y < region.Max.Y; y++ {
+			m.reverseBits(region.Min.X, region.Min.X+k, y, 1, 0)
+			m.reverseBits(region.Min.X+k, region.Max.X, y, 1, 0)
+			m.reverseBits(region.Min.X, region.Max.X, y, 1, 0)
+		}
+	}
+	if k := mod(vector.Y, region.Dy()); k != 0 {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			m.reverseBits(region.Min.Y, region.Min.Y+k, x, 0, 1)
+			m.reverseBits(region.Min.Y+k, region.Max.Y, x, 0, 1)
+			m.reverseBits(region.Min.Y, region.Max.Y, x, 0, 1)
+		}
+	}
+}
+
+// reverseBits reverses the pixels [from, to) along a row (dx == 1) or a column (dy == 1).
+func (m *Mono) reverseBits(from, to, at, dx, dy int) {
+	for i, j := from, to-1; i < j; i, j = i+1, j-1 {
+		xi, yi := at*dy+i*dx, at*dx+i*dy
+		xj, yj := at*dy+j*dx, at*dx+j*dy
+		a, b := m.BitAt(xi, yi), m.BitAt(xj, yj)
+		m.setBit(xi, yi, b)
+		m.setBit(xj, yj, a)
+	}
+}
+
+// Flush implements gfx.DoubleBufferer. If m was not created with
+// NewMonoWithDoubleBuffer, Flush does nothing.
+func (m *Mono) Flush() {
+	if m.doubleBuf == nil {
+		return
+	}
+
+	if m.dirty.Eq(m.Rect) && m.Stride == m.doubleBuf.Stride {
+		copy(m.doubleBuf.Pix, m.Pix)
+	} else {
+		for _, r := range m.dirty.Rects() {
+			// widen each dirty rectangle to whole bytes, so Blit can copy them directly
+			r = r.Sub(m.Rect.Min)
+			if m.Layout == MonoVertical {
+				r.Min.Y &^= 7
+			} else {
+				r.Min.X &^= 7
+			}
+			r = r.Add(m.Rect.Min)
+			m.doubleBuf.Blit(m.SubImage(r), r.Min)
+		}
+	}
+
+	m.dirty.Clear()
+}
+
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+// Changes are only tracked if m has a double buffer.
+func (m *Mono) DirtyRects() []image.Rectangle {
+	return m.dirty.Rects()
+}
+
+func (m *Mono) dirtyAdd(rect image.Rectangle) {
+	if m.doubleBuf == nil {
+		return
+	}
+	m.dirty.Add(rect.Intersect(m.Rect))
+}
+
+// spanMask returns a byte with bits [from, to) set. If msbFirst is true, bit 0
+// is the most significant.
+func spanMask(from, to int, msbFirst bool) uint8 {
+	if to <= from {
+		return 0
+	}
+	mask := uint8(0xFF<<from) & uint8(0xFF>>(8-to))
+	if msbFirst {
+		return bits.Reverse8(mask)
+	}
+	return mask
+}
+
+func setMasked(b *uint8, mask uint8, on bool) {
+	if on {
+		*b |= mask
+	} else {
+		*b &^= mask
+	}
+}
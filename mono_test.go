@@ -0,0 +1,149 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Blitter        = &Mono{}
+	_ Filler         = &Mono{}
+	_ Scroller       = &Mono{}
+	_ RegionScroller = &Mono{}
+	_ VectorScroller = &Mono{}
+	_ DoubleBufferer = &Mono{}
+)
+
+func Test_MonoLayout(t *testing.T) {
+	v := NewMono(image.Rect(0, 0, 128, 64), MonoVertical)
+	v.Set(3, 10, color.White)
+	if v.Pix[128+3] != 0x04 {
+		t.Errorf("vertical: got page byte %08b, want 00000100", v.Pix[128+3])
+	}
+
+	h := NewMono(image.Rect(0, 0, 20, 2), MonoHorizontal)
+	h.Set(9, 1, color.White)
+	if h.Stride != 3 || h.Pix[3+1] != 0x40 {
+		t.Errorf("horizontal: stride %d, got byte %08b, want 01000000", h.Stride, h.Pix[4])
+	}
+
+	// threshold
+	h.Model.Threshold = 0xF000
+	h.Set(0, 0, color.Gray{0xE0})
+	if h.BitAt(0, 0) {
+		t.Error("pixel below threshold was lit")
+	}
+	if h.ColorModel().Convert(color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}) != monoOn {
+		t.Error("white did not convert to on")
+	}
+}
+
+// Test_MonoFastPaths checks the byte-wise paths against setting pixels one at a time.
+func Test_MonoFastPaths(t *testing.T) {
+	rand.Seed(1)
+	for _, layout := range []MonoLayout{MonoVertical, MonoHorizontal} {
+		for i := 0; i < 200; i++ {
+			bounds := image.Rect(0, 0, 1+rand.Intn(40), 1+rand.Intn(40))
+			fast, slow := NewMono(bounds, layout), NewMono(bounds, layout)
+			randomBits(fast, slow)
+
+			r := randomRect(bounds)
+			on := rand.Intn(2) == 0
+			switch rand.Intn(5) {
+			case 0:
+				fast.Fill(r, monoColor(on))
+				forAllPix(r.Intersect(bounds), func(x, y int) { slow.setBit(x, y, on) })
+			case 1:
+				src := NewMono(bounds, layout)
+				randomBits(src, src)
+				at := image.Pt(rand.Intn(8)*8, rand.Intn(8)*8)
+				sub := src.SubImage(r)
+				fast.Blit(sub, at)
+				forAllPix(sub.Bounds(), func(x, y int) {
+					slow.SetBit(x-sub.Bounds().Min.X+at.X, y-sub.Bounds().Min.Y+at.Y, src.BitAt(x, y))
+				})
+			case 2:
+				amount := rand.Intn(2*bounds.Dy()+1) - bounds.Dy()
+				r = bounds
+				if rand.Intn(2) == 0 {
+					r.Min.X, r.Max.X = rand.Intn(bounds.Dx()), bounds.Max.X
+				}
+				fast.RegionScroll(r, amount)
+				ref := NewMono(bounds, layout)
+				ref.Blit(slow, bounds.Min)
+				forAllPix(r, func(x, y int) {
+					if sy := y + amount; sy >= r.Min.Y && sy < r.Max.Y {
+						slow.setBit(x, y, ref.BitAt(x, sy))
+					}
+				})
+			case 3:
+				v := image.Pt(rand.Intn(81)-40, rand.Intn(81)-40)
+				if rand.Intn(2) == 0 {
+					v.Y = 0
+				}
+				r = bounds
+				fast.VectorScroll(r, v)
+				ref := NewMono(bounds, layout)
+				ref.Blit(slow, bounds.Min)
+				forAllPix(r, func(x, y int) {
+					slow.setBit(x, y, ref.BitAt(mod(x+v.X, r.Dx()), mod(y+v.Y, r.Dy())))
+				})
+			case 4:
+				// from an aligned SubImage of itself, overlapping
+				r.Min = image.Pt(r.Min.X&^7, r.Min.Y&^7)
+				at := image.Pt(rand.Intn(3)*8, rand.Intn(3)*8)
+				sub := fast.SubImage(r)
+				ref := NewMono(bounds, layout)
+				ref.Blit(slow, bounds.Min)
+				fast.Blit(sub, at)
+				forAllPix(sub.Bounds(), func(x, y int) {
+					slow.SetBit(x-sub.Bounds().Min.X+at.X, y-sub.Bounds().Min.Y+at.Y, ref.BitAt(x, y))
+				})
+			}
+
+			forAllPix(bounds, func(x, y int) {
+				if fast.BitAt(x, y) != slow.BitAt(x, y) {
+					t.Fatalf("layout %d, iteration %d: pixel (%d,%d) differs", layout, i, x, y)
+				}
+			})
+		}
+	}
+}
+
+func Test_MonoDoubleBuffer(t *testing.T) {
+	front := NewMono(image.Rect(0, 0, 128, 64), MonoVertical)
+	back := NewMonoWithDoubleBuffer(front)
+	back.Fill(image.Rect(10, 13, 20, 17), color.White)
+	if front.BitAt(10, 13) {
+		t.Fatal("front buffer changed before Flush")
+	}
+	back.Flush()
+	if !front.BitAt(10, 13) || !front.BitAt(19, 16) || front.BitAt(20, 17) {
+		t.Fatal("Flush did not copy the dirty area")
+	}
+}
+
+func monoColor(on bool) color.Color {
+	if on {
+		return color.White
+	}
+	return color.Black
+}
+
+func randomBits(a, b *Mono) {
+	forAllPix(a.Rect, func(x, y int) {
+		on := rand.Intn(2) == 0
+		a.setBit(x, y, on)
+		b.setBit(x, y, on)
+	})
+}
+
+func randomRect(bounds image.Rectangle) image.Rectangle {
+	return image.Rect(
+		rand.Intn(bounds.Dx()+4)-2, rand.Intn(bounds.Dy()+4)-2,
+		rand.Intn(bounds.Dx()+4)-2, rand.Intn(bounds.Dy()+4)-2,
+	)
+}
@@ -0,0 +1,261 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+// Paletted wraps an *image.Paletted and implements the gfx interfaces. Colors are
+// looked up in the palette once per Fill or Blit, not once per pixel.
+//
+// Changing a palette entry through SetPaletteColor changes every pixel using it,
+// so it marks the whole image dirty. Cycling palette entries is a cheap way of
+// animating on devices with little memory.
+type Paletted struct {
+	*image.Paletted
+	// ScrollFill decides what happens to the area vacated by scrolling.
+	ScrollFill ScrollFill
+	doubleBuf  *image.Paletted
+	dirty      Region
+}
+
+func NewPaletted(base *image.Paletted) *Paletted {
+	return &Paletted{Paletted: base}
+}
+
+// NewPalettedWithDoubleBuffer returns a Paletted that buffers all changes, to both
+// pixels and palette; they are copied into base when Flush is called.
+func NewPalettedWithDoubleBuffer(base *image.Paletted) *Paletted {
+	p := &Paletted{
+		Paletted:  image.NewPaletted(base.Bounds(), slices.Clone(base.Palette)),
+		doubleBuf: base,
+	}
+	for y := base.Rect.Min.Y; y < base.Rect.Max.Y; y++ {
+		copy(p.Pix[p.PixOffset(base.Rect.Min.X, y):], base.Pix[base.PixOffset(base.Rect.Min.X, y):base.PixOffset(base.Rect.Max.X, y)])
+	}
+	return p
+}
+
+func (p *Paletted) Set(x, y int, c color.Color) {
+	p.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	p.Paletted.Set(x, y, c)
+}
+
+func (p *Paletted) SetColorIndex(x, y int, index uint8) {
+	p.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	p.Paletted.SetColorIndex(x, y, index)
+}
+
+// SetPaletteColor changes palette entry i to c and marks the whole image dirty.
+// An i past the end of the palette is ignored.
+func (p *Paletted) SetPaletteColor(i uint8, c color.Color) {
+	if int(i) >= len(p.Palette) {
+		return
+	}
+	p.Palette[i] = c
+	p.dirtyAll()
+}
+
+// SetPalette replaces the whole palette and marks the whole image dirty.
+func (p *Paletted) SetPalette(palette color.Palette) {
+	p.Palette = palette
+	p.dirtyAll()
+}
+
+// RotatePalette cycles palette entries [from, to) by amount places, such that
+// entry i takes on the color of entry i+amount. Pixels are not touched. The
+// range is clamped to the palette.
+func (p *Paletted) RotatePalette(from, to, amount int) {
+	from, to = min(max(from, 0), len(p.Palette)), min(max(to, 0), len(p.Palette))
+	if to-from < 2 {
+		return
+	}
+	k := mod(amount, to-from)
+	if k == 0 {
+		return
+	}
+	entries := p.Palette[from:to]
+	slices.Reverse(entries[:k])
+	slices.Reverse(entries[k:])
+	slices.Reverse(entries)
+	p.dirtyAll()
+}
+
+// Fill implements gfx.Filler.
+func (p *Paletted) Fill(where image.Rectangle, c color.Color) {
+	where = p.Rect.Intersect(where)
+	if where.Empty() {
+		return
+	}
+	p.dirtyAdd(where)
+
+	index := uint8(p.Palette.Index(c))
+	for y := where.Min.Y; y < where.Max.Y; y++ {
+		i := p.PixOffset(where.Min.X, y)
+		fillRow(p.Pix[i:i+where.Dx()], []byte{index})
+	}
+}
+
+// Blit implements gfx.Blitter. Paletted sources are copied row by row if they
+// share the same palette, and remapped one palette entry at a time otherwise.
+func (p *Paletted) Blit(src image.Image, where image.Point) {
+	destRect, sp := clipBlit(p.Rect, src.Bounds(), where)
+	if destRect.Empty() {
+		return
+	}
+	p.dirtyAdd(destRect)
+
+	var s *image.Paletted
+	switch src := src.(type) {
+	case *Paletted:
+		s = src.Paletted
+	case *image.Paletted:
+		s = src
+	}
+
+	if s == nil {
+		for y := 0; y < destRect.Dy(); y++ {
+			i := p.PixOffset(destRect.Min.X, destRect.Min.Y+y)
+			for x := 0; x < destRect.Dx(); x++ {
+				p.Pix[i+x] = uint8(p.Palette.Index(src.At(sp.X+x, sp.Y+y)))
+			}
+		}
+		return
+	}
+
+	width := destRect.Dx()
+	overlap := samePix(s.Pix, p.Pix)
+	if samePalette(p.Palette, s.Palette) {
+		if overlap && sp.Y < destRect.Min.Y {
+			// overlapping copy within ourselves; go bottom up
+			for y := destRect.Dy() - 1; y >= 0; y-- {
+				d, o := p.PixOffset(destRect.Min.X, destRect.Min.Y+y), s.PixOffset(sp.X, sp.Y+y)
+				copy(p.Pix[d:d+width], s.Pix[o:o+width])
+			}
+			return
+		}
+		for y := 0; y < destRect.Dy(); y++ {
+			d, o := p.PixOffset(destRect.Min.X, destRect.Min.Y+y), s.PixOffset(sp.X, sp.Y+y)
+			copy(p.Pix[d:d+width], s.Pix[o:o+width])
+		}
+		return
+	}
+
+	// build a lookup table from their palette to ours
+	var remap [256]uint8
+	for i, c := range s.Palette {
+		remap[i] = uint8(p.Palette.Index(c))
+	}
+	// a view of our own pixels through another palette must be read before
+	// it is written over, so go the right way round
+	yStart, yEnd, yStep := 0, destRect.Dy(), 1
+	if overlap && sp.Y < destRect.Min.Y {
+		yStart, yEnd, yStep = destRect.Dy()-1, -1, -1
+	}
+	xStart, xEnd, xStep := 0, width, 1
+	if overlap && sp.X < destRect.Min.X {
+		xStart, xEnd, xStep = width-1, -1, -1
+	}
+	for y := yStart; y != yEnd; y += yStep {
+		d, o := p.PixOffset(destRect.Min.X, destRect.Min.Y+y), s.PixOffset(sp.X, sp.Y+y)
+		for x := xStart; x != xEnd; x += xStep {
+			p.Pix[d+x] = remap[s.Pix[o+x]]
+		}
+	}
+}
+
+func samePalette(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Scroll implements gfx.Scroller.
+func (p *Paletted) Scroll(amount int) {
+	p.RegionScroll(p.Rect, amount)
+}
+
+// RegionScroll implements gfx.RegionScroller.
+func (p *Paletted) RegionScroll(region image.Rectangle, amount int) {
+	region = p.Rect.Intersect(region)
+	if region.Empty() || amount == 0 {
+		return
+	}
+	p.dirtyAdd(region)
+	defer p.ScrollFill.apply(p, region, image.Pt(0, amount))
+
+	scrollRows(p.Pix, p.Stride, p.PixOffset(region.Min.X, region.Min.Y), region.Dx(), region.Dy(), amount)
+}
+
+// VectorScroll implements gfx.VectorScroller. Unless ScrollFill says otherwise,
+// pixels scrolled off one edge of region wrap around to the opposite edge.
+func (p *Paletted) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = p.Rect.Intersect(region)
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	p.dirtyAdd(region)
+	defer p.ScrollFill.apply(p, region, vector)
+
+	rotateRows(p.Pix, p.Stride, p.PixOffset(region.Min.X, region.Min.Y), region.Dx(), region.Dy(), 1, vector)
+}
+
+// Flush implements gfx.DoubleBufferer. The palette is copied along with the
+// pixels. Without a double buffer it just marks everything clean.
+func (p *Paletted) Flush() {
+	if p.doubleBuf == nil {
+		p.dirty.Clear()
+		return
+	}
+
+	if p.dirty.Eq(p.Rect) {
+		p.doubleBuf.Palette = append(p.doubleBuf.Palette[:0], p.Palette...)
+		if p.Stride == p.doubleBuf.Stride {
+			copy(p.doubleBuf.Pix, p.Pix)
+		} else {
+			p.flush(p.Rect)
+		}
+	} else {
+		for _, r := range p.dirty.Rects() {
+			p.flush(r)
+		}
+	}
+
+	p.dirty.Clear()
+}
+
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+// A driver managing its own transfers can send just these, then call Flush.
+func (p *Paletted) DirtyRects() []image.Rectangle {
+	return p.dirty.Rects()
+}
+
+func (p *Paletted) flush(rect image.Rectangle) {
+	width := rect.Dx()
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		src, dst := p.PixOffset(rect.Min.X, y), p.doubleBuf.PixOffset(rect.Min.X, y)
+		copy(p.doubleBuf.Pix[dst:dst+width:dst+width], p.Pix[src:src+width:src+width])
+	}
+}
+
+func (p *Paletted) dirtyAll() {
+	if p.doubleBuf == nil {
+		return
+	}
+	p.dirty.Clear()
+	p.dirty.Add(p.Rect)
+}
+
+func (p *Paletted) dirtyAdd(rect image.Rectangle) {
+	if p.doubleBuf == nil {
+		return
+	}
+	p.dirty.Add(rect.Intersect(p.Rect))
+}
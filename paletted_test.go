@@ -0,0 +1,127 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Blitter        = &Paletted{}
+	_ Filler         = &Paletted{}
+	_ Scroller       = &Paletted{}
+	_ RegionScroller = &Paletted{}
+	_ VectorScroller = &Paletted{}
+	_ DoubleBufferer = &Paletted{}
+)
+
+var testPalette = color.Palette{
+	color.RGBA{0, 0, 0, 0xFF},
+	color.RGBA{0xFF, 0, 0, 0xFF},
+	color.RGBA{0, 0xFF, 0, 0xFF},
+	color.RGBA{0, 0, 0xFF, 0xFF},
+}
+
+func Test_PalettedFillBlit(t *testing.T) {
+	p := NewPaletted(image.NewPaletted(image.Rect(0, 0, 8, 8), slices.Clone(testPalette)))
+	p.Fill(image.Rect(2, 2, 6, 6), color.RGBA{0xF0, 0x10, 0x10, 0xFF})
+	if p.ColorIndexAt(2, 2) != 1 || p.ColorIndexAt(5, 5) != 1 || p.ColorIndexAt(6, 6) != 0 {
+		t.Fatal("Fill did not map to the nearest palette entry")
+	}
+
+	// a source with the palette in a different order gets remapped
+	other := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{testPalette[3], testPalette[2]})
+	other.SetColorIndex(0, 0, 1)
+	p.Blit(other, image.Pt(6, 6))
+	if p.ColorIndexAt(6, 6) != 2 || p.ColorIndexAt(7, 7) != 3 {
+		t.Errorf("remapped Blit gave indexes %d and %d", p.ColorIndexAt(6, 6), p.ColorIndexAt(7, 7))
+	}
+
+	// and one with the same palette is copied as is
+	q := NewPaletted(image.NewPaletted(image.Rect(0, 0, 8, 8), slices.Clone(testPalette)))
+	q.Blit(p, image.Pt(-2, -2))
+	if q.ColorIndexAt(0, 0) != 1 || q.ColorIndexAt(5, 5) != 3 {
+		t.Error("Blit did not copy indexes")
+	}
+
+	// blitted onto its own pixels, through a SubImage or a view with another
+	// palette, overlapping either way
+	reversed := slices.Clone(testPalette)
+	slices.Reverse(reversed)
+	for _, tc := range []struct {
+		sr image.Rectangle
+		at image.Point
+	}{
+		{image.Rect(0, 0, 4, 7), image.Pt(0, 1)},
+		{image.Rect(2, 3, 8, 8), image.Pt(1, 0)},
+		{image.Rect(1, 1, 6, 5), image.Pt(3, 2)},
+	} {
+		for _, remapped := range []bool{false, true} {
+			p := NewPaletted(image.NewPaletted(image.Rect(0, 0, 8, 8), slices.Clone(testPalette)))
+			for i := range p.Pix {
+				p.Pix[i] = uint8((i%8*3 + i/8) % 4)
+			}
+			before := slices.Clone(p.Pix)
+			var src image.Image = p.SubImage(tc.sr)
+			if remapped {
+				view := *p.Paletted
+				view.Palette = reversed
+				src = view.SubImage(tc.sr)
+			}
+			p.Blit(src, tc.at)
+
+			moved := tc.sr.Sub(tc.sr.Min).Add(tc.at)
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					want := before[p.PixOffset(x, y)]
+					if pt := image.Pt(x, y); pt.In(moved) {
+						from := pt.Sub(tc.at).Add(tc.sr.Min)
+						if want = before[p.PixOffset(from.X, from.Y)]; remapped {
+							want = 3 - want
+						}
+					}
+					if got := p.ColorIndexAt(x, y); got != want {
+						t.Fatalf("self Blit of %v to %v, remapped %v: index at (%d,%d) is %d, want %d", tc.sr, tc.at, remapped, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_PalettedCycling(t *testing.T) {
+	front := image.NewPaletted(image.Rect(0, 0, 8, 8), slices.Clone(testPalette))
+	p := NewPalettedWithDoubleBuffer(front)
+	p.SetColorIndex(1, 1, 1)
+	p.Flush()
+
+	p.RotatePalette(1, 4, 1)
+	if front.At(1, 1) != testPalette[1] {
+		t.Fatal("front palette changed before Flush")
+	}
+	p.Flush()
+	if front.At(1, 1) != testPalette[2] || front.At(0, 0) != testPalette[0] {
+		t.Errorf("after cycling, got %v and %v", front.At(1, 1), front.At(0, 0))
+	}
+
+	p.SetPaletteColor(0, color.White)
+	if !p.dirty.Eq(p.Rect) {
+		t.Error("changing a palette entry did not dirty the whole image")
+	}
+	p.Flush()
+	if front.At(7, 7) != color.White {
+		t.Error("palette change was not flushed")
+	}
+
+	// entries outside the palette are left alone rather than panicking
+	p.SetPaletteColor(200, color.Black)
+	p.RotatePalette(-3, 2, 1)
+	p.RotatePalette(2, 300, 1)
+	p.Flush()
+	want := color.Palette{testPalette[2], color.White, testPalette[1], testPalette[3]}
+	if !samePalette(front.Palette, want) {
+		t.Errorf("after clamped cycling, palette is %v, want %v", front.Palette, want)
+	}
+}
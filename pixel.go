@@ -1,17 +1,40 @@
 package gfx
 
 import (
+	"encoding/binary"
 	"image/color"
 	"math/bits"
 )
 
+// hostLittleEndian is true when the host stores the low byte of a word first.
+var hostLittleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// bigEndian16 converts between a 16 bit value and the native integer that, when
+// stored to memory, lays it out high byte first. It is its own inverse.
+func bigEndian16(v uint16) uint16 {
+	if hostLittleEndian {
+		// This is done using a single instruction on ARM (rev16).
+		return bits.ReverseBytes16(v)
+	}
+	return v
+}
+
+// littleEndian16 is like bigEndian16, but for laying values out low byte first.
+func littleEndian16(v uint16) uint16 {
+	if hostLittleEndian {
+		return v
+	}
+	return bits.ReverseBytes16(v)
+}
+
 // RGB565 as 'borrowed' from github.com/tinygo-org/drivers/pixel/pixel.go
 //
-// RGB565 as used in many SPI displays. Stored as a big endian value.
+// RGB565BE is RGB565 as used in many SPI displays. Stored as a big endian value.
 //
-// The color format in integer form is gggbbbbb_rrrrrggg on little endian
-// systems, which is the standard RGB565 format but with the top and bottom
-// bytes swapped.
+// On little endian systems the color format in integer form is gggbbbbb_rrrrrggg,
+// which is the standard RGB565 format but with the top and bottom bytes swapped.
+// On big endian systems it is the standard rrrrrggg_gggbbbbb. Either way, storing
+// it to memory results in the high byte first, ready for sending to a display.
 //
 // There are a few alternatives to this weird big-endian format, but they're not
 // great:
@@ -26,31 +49,15 @@ type RGB565BE uint16
 var RGB565BEModel = color.ModelFunc(rgb565BEModelFunc)
 
 func rgb565BEModelFunc(c color.Color) color.Color {
-	r, g, b, _ := c.RGBA()
-
-	val := uint16((r/0x101)&0xF8)<<8 +
-		uint16((g/0x101)&0xFC)<<3 +
-		uint16((b/0x101)&0xF8)>>3
-	// Swap endianness (make big endian).
-	// This is done using a single instruction on ARM (rev16).
-	// TODO: this should only be done on little endian systems, but TinyGo
-	// doesn't currently (2023) support big endian systems so it's difficult to
-	// test. Also, big endian systems don't seem fasionable these days.
-	val = bits.ReverseBytes16(val)
-	return RGB565BE(val)
+	if native, ok := c.(RGB565BE); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewRGB565BE(r, g, b)
 }
 
 func NewRGB565BE(r, g, b uint8) RGB565BE {
-	val := uint16(r&0xF8)<<8 +
-		uint16(g&0xFC)<<3 +
-		uint16(b&0xF8)>>3
-	// Swap endianness (make big endian).
-	// This is done using a single instruction on ARM (rev16).
-	// TODO: this should only be done on little endian systems, but TinyGo
-	// doesn't currently (2023) support big endian systems so it's difficult to
-	// test. Also, big endian systems don't seem fasionable these days.
-	val = bits.ReverseBytes16(val)
-	return RGB565BE(val)
+	return RGB565BE(bigEndian16(pack565(r, g, b)))
 }
 
 func (c RGB565BE) BitsPerPixel() int {
@@ -63,26 +70,246 @@ func (c RGB565BE) Convert(in color.Color) color.Color {
 }
 
 func (c RGB565BE) RGBA() (r, g, b, a uint32) {
-	// Note: on ARM, the compiler uses a rev instruction instead of a rev16
-	// instruction. I wonder whether this can be optimized further to use rev16
-	// instead?
-	c = c<<8 | c>>8
+	r, g, b = unpack565(bigEndian16(uint16(c)))
+	return r, g, b, 0xFFFF
+}
+
+// RGB565LE is RGB565 stored as a little endian value: storing it to memory
+// results in the low byte first. Some parallel and MIPI displays want this.
+type RGB565LE uint16
+
+var RGB565LEModel = color.ModelFunc(rgb565LEModelFunc)
+
+func rgb565LEModelFunc(c color.Color) color.Color {
+	if native, ok := c.(RGB565LE); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewRGB565LE(r, g, b)
+}
+
+func NewRGB565LE(r, g, b uint8) RGB565LE {
+	return RGB565LE(littleEndian16(pack565(r, g, b)))
+}
+
+func (c RGB565LE) BitsPerPixel() int {
+	return 16
+}
+
+// Convert makes RGB565LE implement color.ColorModel
+func (c RGB565LE) Convert(in color.Color) color.Color {
+	return rgb565LEModelFunc(in)
+}
+
+func (c RGB565LE) RGBA() (r, g, b, a uint32) {
+	r, g, b = unpack565(littleEndian16(uint16(c)))
+	return r, g, b, 0xFFFF
+}
+
+// BGR565 is RGB565 with red and blue swapped (bbbbbggg_gggrrrrr), as used by
+// controllers wired or configured for BGR order. Like RGB565BE, it is stored
+// as a big endian value.
+type BGR565 uint16
+
+var BGR565Model = color.ModelFunc(bgr565ModelFunc)
+
+func bgr565ModelFunc(c color.Color) color.Color {
+	if native, ok := c.(BGR565); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewBGR565(r, g, b)
+}
+
+func NewBGR565(r, g, b uint8) BGR565 {
+	return BGR565(bigEndian16(pack565(b, g, r)))
+}
+
+func (c BGR565) BitsPerPixel() int {
+	return 16
+}
+
+// Convert makes BGR565 implement color.ColorModel
+func (c BGR565) Convert(in color.Color) color.Color {
+	return bgr565ModelFunc(in)
+}
+
+func (c BGR565) RGBA() (r, g, b, a uint32) {
+	b, g, r = unpack565(bigEndian16(uint16(c)))
+	return r, g, b, 0xFFFF
+}
+
+// RGB555 is a 15 bit color (xrrrrrgg_gggbbbbb), with the top bit unused. Like
+// RGB565BE, it is stored as a big endian value.
+type RGB555 uint16
+
+var RGB555Model = color.ModelFunc(rgb555ModelFunc)
+
+func rgb555ModelFunc(c color.Color) color.Color {
+	if native, ok := c.(RGB555); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewRGB555(r, g, b)
+}
+
+func NewRGB555(r, g, b uint8) RGB555 {
+	val := uint16(r>>3)<<10 | uint16(g>>3)<<5 | uint16(b>>3)
+	return RGB555(bigEndian16(val))
+}
+
+func (c RGB555) BitsPerPixel() int {
+	return 15
+}
+
+// Convert makes RGB555 implement color.ColorModel
+func (c RGB555) Convert(in color.Color) color.Color {
+	return rgb555ModelFunc(in)
+}
+
+func (c RGB555) RGBA() (r, g, b, a uint32) {
+	val := bigEndian16(uint16(c))
+	return expand5(val >> 10), expand5(val >> 5), expand5(val), 0xFFFF
+}
+
+// RGB444 is a 12 bit color (xxxxrrrr_ggggbbbb), with the top four bits unused.
+// Like RGB565BE, it is stored as a big endian value.
+type RGB444 uint16
 
-	r = uint32(uint8(c>>11) << 3)
-	g = uint32(uint8(c>>5) << 2)
-	b = uint32(uint8(c&0xff) << 3)
-	a = 255 * 0x101
+var RGB444Model = color.ModelFunc(rgb444ModelFunc)
 
-	// Correct color rounding, so that 0xff roundtrips back to 0xff.
+func rgb444ModelFunc(c color.Color) color.Color {
+	if native, ok := c.(RGB444); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewRGB444(r, g, b)
+}
+
+func NewRGB444(r, g, b uint8) RGB444 {
+	val := uint16(r>>4)<<8 | uint16(g>>4)<<4 | uint16(b>>4)
+	return RGB444(bigEndian16(val))
+}
+
+func (c RGB444) BitsPerPixel() int {
+	return 12
+}
+
+// Convert makes RGB444 implement color.ColorModel
+func (c RGB444) Convert(in color.Color) color.Color {
+	return rgb444ModelFunc(in)
+}
+
+func (c RGB444) RGBA() (r, g, b, a uint32) {
+	val := bigEndian16(uint16(c))
+	return expand4(val >> 8), expand4(val >> 4), expand4(val), 0xFFFF
+}
+
+// RGB332 is an 8 bit color (rrrgggbb).
+type RGB332 uint8
+
+var RGB332Model = color.ModelFunc(rgb332ModelFunc)
+
+func rgb332ModelFunc(c color.Color) color.Color {
+	if native, ok := c.(RGB332); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewRGB332(r, g, b)
+}
+
+func NewRGB332(r, g, b uint8) RGB332 {
+	return RGB332(r&0xE0 | (g&0xE0)>>3 | b>>6)
+}
+
+func (c RGB332) BitsPerPixel() int {
+	return 8
+}
+
+// Convert makes RGB332 implement color.ColorModel
+func (c RGB332) Convert(in color.Color) color.Color {
+	return rgb332ModelFunc(in)
+}
+
+func (c RGB332) RGBA() (r, g, b, a uint32) {
+	return expand3(uint16(c) >> 5), expand3(uint16(c) >> 2), expand2(uint16(c)), 0xFFFF
+}
+
+// RGB666 is an 18 bit color in the 3 byte form most controllers take it in:
+// one byte per channel, red first, with each value in the top 6 bits.
+type RGB666 [3]uint8
+
+var RGB666Model = color.ModelFunc(rgb666ModelFunc)
 
-	r |= r >> 5
-	g |= g >> 6
-	b |= b >> 5
+func rgb666ModelFunc(c color.Color) color.Color {
+	if native, ok := c.(RGB666); ok {
+		return native
+	}
+	r, g, b := rgb8(c)
+	return NewRGB666(r, g, b)
+}
+
+func NewRGB666(r, g, b uint8) RGB666 {
+	return RGB666{r & 0xFC, g & 0xFC, b & 0xFC}
+}
+
+// BitsPerPixel returns 24, not 18, as that is how much space an RGB666 takes up
+// in memory and on the wire.
+func (c RGB666) BitsPerPixel() int {
+	return 24
+}
+
+// Convert makes RGB666 implement color.ColorModel
+func (c RGB666) Convert(in color.Color) color.Color {
+	return rgb666ModelFunc(in)
+}
+
+func (c RGB666) RGBA() (r, g, b, a uint32) {
+	return expand6(uint16(c[0]) >> 2), expand6(uint16(c[1]) >> 2), expand6(uint16(c[2]) >> 2), 0xFFFF
+}
 
-	// RGBA() returns values scalled to 16bits, so have to multiply by 0x101
-	r *= 0x101
-	g *= 0x101
-	b *= 0x101
+// rgb8 returns the 8 bit red, green and blue of c.
+func rgb8(c color.Color) (r, g, b uint8) {
+	if rgba, ok := c.(color.RGBA); ok {
+		return rgba.R, rgba.G, rgba.B
+	}
+	r32, g32, b32, _ := c.RGBA()
+	return uint8(r32 >> 8), uint8(g32 >> 8), uint8(b32 >> 8)
+}
+
+// pack565 packs 8 bit channels into a native rrrrrggg_gggbbbbb.
+func pack565(r, g, b uint8) uint16 {
+	return uint16(r&0xF8)<<8 | uint16(g&0xFC)<<3 | uint16(b)>>3
+}
+
+// unpack565 returns the 16 bit channels of a native rrrrrggg_gggbbbbb.
+func unpack565(val uint16) (r, g, b uint32) {
+	return expand5(val >> 11), expand6(val >> 5), expand5(val)
+}
+
+// The below expand the low bits of v to a full 16 bit channel, such that 0 maps
+// to 0 and all bits set maps to 0xFFFF. The top bits are replicated in to the
+// low bits, so truncating the result back down recovers v exactly.
+
+func expand2(v uint16) uint32 {
+	return uint32(v&0x3) * 0x5555
+}
+
+func expand3(v uint16) uint32 {
+	v &= 0x7
+	return uint32(v<<5|v<<2|v>>1) * 0x101
+}
+
+func expand4(v uint16) uint32 {
+	return uint32(v&0xF) * 0x1111
+}
+
+func expand5(v uint16) uint32 {
+	v &= 0x1F
+	return uint32(v<<3|v>>2) * 0x101
+}
 
-	return
+func expand6(v uint16) uint32 {
+	v &= 0x3F
+	return uint32(v<<2|v>>4) * 0x101
 }
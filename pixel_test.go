@@ -1,6 +1,7 @@
 package gfx
 
 import (
+	"encoding/binary"
 	"fmt"
 	"image"
 	"image/color"
@@ -54,3 +55,83 @@ func Test_Gradient888(t *testing.T) {
 
 	save("gradient-888.png", screen)
 }
+
+// Test_PixelRoundTrip checks that every packed color survives a trip through
+// RGBA and back, and that the 8 bit channels it stands for come out exactly.
+func Test_PixelRoundTrip(t *testing.T) {
+	models := map[string]color.Model{
+		"RGB565BE": RGB565BEModel,
+		"RGB565LE": RGB565LEModel,
+		"BGR565":   BGR565Model,
+		"RGB555":   RGB555Model,
+		"RGB444":   RGB444Model,
+		"RGB332":   RGB332Model,
+		"RGB666":   RGB666Model,
+	}
+
+	rand.Seed(0)
+	for name, model := range models {
+		for i := 0; i < 1024; i++ {
+			packed := model.Convert(randomColor())
+			again := model.Convert(packed)
+			if again != packed {
+				t.Errorf("%s: %v converted to %v", name, packed, again)
+			}
+
+			// the 8 bit value must convert back to the same thing too
+			r, g, b, a := packed.RGBA()
+			if a != 0xFFFF || r%0x101 != 0 || g%0x101 != 0 || b%0x101 != 0 {
+				t.Errorf("%s: %v has RGBA %x %x %x %x", name, packed, r, g, b, a)
+			}
+			if c := model.Convert(color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 0xFF}); c != packed {
+				t.Errorf("%s: %v came back as %v via color.RGBA", name, packed, c)
+			}
+		}
+
+		// the extremes are exact
+		for _, c := range []color.RGBA{{0, 0, 0, 0xFF}, {0xFF, 0xFF, 0xFF, 0xFF}, {0xFF, 0, 0, 0xFF}, {0, 0, 0xFF, 0xFF}} {
+			r, g, b, _ := model.Convert(c).RGBA()
+			if r>>8 != uint32(c.R) || g>>8 != uint32(c.G) || b>>8 != uint32(c.B) {
+				t.Errorf("%s: %v came back as %x %x %x", name, c, r, g, b)
+			}
+		}
+	}
+}
+
+func Test_PixelLayout(t *testing.T) {
+	red565 := [2]byte{}
+	binary.NativeEndian.PutUint16(red565[:], uint16(NewRGB565BE(0xFF, 0, 0)))
+	if red565 != [2]byte{0xF8, 0x00} {
+		t.Errorf("RGB565BE red stored as % x", red565)
+	}
+	binary.NativeEndian.PutUint16(red565[:], uint16(NewRGB565LE(0xFF, 0, 0)))
+	if red565 != [2]byte{0x00, 0xF8} {
+		t.Errorf("RGB565LE red stored as % x", red565)
+	}
+	binary.NativeEndian.PutUint16(red565[:], uint16(NewBGR565(0xFF, 0, 0)))
+	if red565 != [2]byte{0x00, 0x1F} {
+		t.Errorf("BGR565 red stored as % x", red565)
+	}
+	if c := NewRGB332(0xFF, 0, 0xFF); c != 0xE3 {
+		t.Errorf("RGB332 magenta is %08b", c)
+	}
+	if c := NewRGB666(0xFF, 0x81, 0x03); c != (RGB666{0xFC, 0x80, 0x00}) {
+		t.Errorf("RGB666 is % x", c)
+	}
+}
+
+func Test_PixelSoftScreen(t *testing.T) {
+	screen := NewSoftScreenOf[RGB332](image.Rect(0, 0, 8, 8), image.Rect(0, 0, 16, 16), image.Rect(0, 0, 16, 16))
+	screen.Convert = RGB332Model
+	screen.Set(1, 1, color.White)
+	if screen.At(1, 1) != RGB332(0xFF) {
+		t.Errorf("got %v", screen.At(1, 1))
+	}
+
+	screen666 := NewSoftScreenOf[RGB666](image.Rect(0, 0, 8, 8), image.Rect(0, 0, 16, 16), image.Rect(0, 0, 16, 16))
+	screen666.Convert = RGB666Model
+	screen666.Fill(screen666.Bounds(), color.RGBA{0x10, 0x20, 0x30, 0xFF})
+	if screen666.At(15, 15) != NewRGB666(0x10, 0x20, 0x30) {
+		t.Errorf("got %v", screen666.At(15, 15))
+	}
+}
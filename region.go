@@ -0,0 +1,231 @@
+package gfx
+
+import "image"
+
+// DefaultMaxRects is how many rectangles a Region holds when its MaxRects is zero.
+const DefaultMaxRects = 16
+
+// Region is an area made up of a set of non-overlapping rectangles. It is used
+// to track damage: a framebuffer adds every rectangle it draws to, and when
+// flushing it copies only what is in the Region.
+//
+// To keep the cost of adding to a Region bounded, it never holds more than
+// MaxRects rectangles. Past that, the two rectangles whose bounding box wastes
+// the least area are merged. Rectangles sharing a whole edge are always merged.
+//
+// The zero value is an empty Region ready to use.
+type Region struct {
+	// MaxRects is the most rectangles the Region will hold. Zero means
+	// DefaultMaxRects.
+	MaxRects int
+
+	rects []image.Rectangle
+}
+
+// Rects returns the rectangles making up the region. They do not overlap. The
+// returned slice is only valid until the region is next changed.
+func (rg *Region) Rects() []image.Rectangle {
+	return rg.rects
+}
+
+// Empty reports whether the region covers nothing.
+func (rg *Region) Empty() bool {
+	return len(rg.rects) == 0
+}
+
+// Bounds returns the smallest rectangle containing the whole region.
+func (rg *Region) Bounds() image.Rectangle {
+	var b image.Rectangle
+	for _, r := range rg.rects {
+		b = b.Union(r)
+	}
+	return b
+}
+
+// Area returns the number of pixels covered by the region.
+func (rg *Region) Area() int {
+	var area int
+	for _, r := range rg.rects {
+		area += r.Dx() * r.Dy()
+	}
+	return area
+}
+
+// Contains reports whether pt is within the region.
+func (rg *Region) Contains(pt image.Point) bool {
+	for _, r := range rg.rects {
+		if pt.In(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Eq reports whether the region covers exactly r.
+func (rg *Region) Eq(r image.Rectangle) bool {
+	if r.Empty() {
+		return rg.Empty()
+	}
+	return rg.Area() == r.Dx()*r.Dy() && r.Union(rg.Bounds()).Eq(r)
+}
+
+// Clear empties the region.
+func (rg *Region) Clear() {
+	rg.rects = rg.rects[:0]
+}
+
+// Add adds r to the region.
+func (rg *Region) Add(r image.Rectangle) {
+	if rg.add(r) {
+		rg.budget()
+	}
+}
+
+// add adds r to the region without regard to MaxRects, and reports whether
+// anything changed.
+func (rg *Region) add(r image.Rectangle) bool {
+	if r.Empty() {
+		return false
+	}
+
+	// Most additions are small and land somewhere already covered.
+	for _, have := range rg.rects {
+		if r.In(have) {
+			return false
+		}
+	}
+
+	// Anything entirely within r can go; what's left is cut down to its parts
+	// outside r, so that r can be added whole.
+	kept := rg.rects[:0]
+	var pieces []image.Rectangle
+	for _, have := range rg.rects {
+		switch {
+		case have.In(r):
+		case have.Overlaps(r):
+			pieces = appendSubtract(pieces, have, r)
+		default:
+			kept = append(kept, have)
+		}
+	}
+	rg.rects = append(append(kept, pieces...), r)
+
+	rg.coalesce()
+	return true
+}
+
+// Union adds all of o to the region.
+func (rg *Region) Union(o *Region) {
+	for _, r := range o.rects {
+		rg.Add(r)
+	}
+}
+
+// Intersect clips the region to r.
+func (rg *Region) Intersect(r image.Rectangle) {
+	kept := rg.rects[:0]
+	for _, have := range rg.rects {
+		if have = have.Intersect(r); !have.Empty() {
+			kept = append(kept, have)
+		}
+	}
+	rg.rects = kept
+}
+
+// Subtract removes r from the region.
+func (rg *Region) Subtract(r image.Rectangle) {
+	if r.Empty() {
+		return
+	}
+	kept := rg.rects[:0]
+	var pieces []image.Rectangle
+	for _, have := range rg.rects {
+		if have.Overlaps(r) {
+			pieces = appendSubtract(pieces, have, r)
+		} else {
+			kept = append(kept, have)
+		}
+	}
+	rg.rects = append(kept, pieces...)
+	rg.coalesce()
+	rg.budget()
+}
+
+// appendSubtract appends the (up to four) parts of a that lie outside b.
+func appendSubtract(dst []image.Rectangle, a, b image.Rectangle) []image.Rectangle {
+	b = b.Intersect(a)
+	if b.Empty() {
+		return append(dst, a)
+	}
+	// full width bands above and below
+	if b.Min.Y > a.Min.Y {
+		dst = append(dst, image.Rect(a.Min.X, a.Min.Y, a.Max.X, b.Min.Y))
+	}
+	if b.Max.Y < a.Max.Y {
+		dst = append(dst, image.Rect(a.Min.X, b.Max.Y, a.Max.X, a.Max.Y))
+	}
+	// and what's left either side
+	if b.Min.X > a.Min.X {
+		dst = append(dst, image.Rect(a.Min.X, b.Min.Y, b.Min.X, b.Max.Y))
+	}
+	if b.Max.X < a.Max.X {
+		dst = append(dst, image.Rect(b.Max.X, b.Min.Y, a.Max.X, b.Max.Y))
+	}
+	return dst
+}
+
+// coalesce merges rectangles that share a whole edge, until none do.
+func (rg *Region) coalesce() {
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(rg.rects); i++ {
+			for j := i + 1; j < len(rg.rects); j++ {
+				a, b := rg.rects[i], rg.rects[j]
+				if (a.Min.X == b.Min.X && a.Max.X == b.Max.X && (a.Max.Y == b.Min.Y || b.Max.Y == a.Min.Y)) ||
+					(a.Min.Y == b.Min.Y && a.Max.Y == b.Max.Y && (a.Max.X == b.Min.X || b.Max.X == a.Min.X)) {
+					rg.rects[i] = a.Union(b)
+					rg.rects = append(rg.rects[:j], rg.rects[j+1:]...)
+					merged = true
+					j--
+				}
+			}
+		}
+	}
+}
+
+// budget merges rectangles until there are no more than MaxRects.
+func (rg *Region) budget() {
+	limit := rg.MaxRects
+	if limit <= 0 {
+		limit = DefaultMaxRects
+	}
+
+	for len(rg.rects) > limit {
+		before := len(rg.rects)
+
+		// find the pair whose bounding box wastes the least
+		bi, bj, best := 0, 1, -1
+		for i := range rg.rects {
+			for j := i + 1; j < len(rg.rects); j++ {
+				a, b := rg.rects[i], rg.rects[j]
+				u := a.Union(b)
+				waste := u.Dx()*u.Dy() - a.Dx()*a.Dy() - b.Dx()*b.Dy()
+				if best < 0 || waste < best {
+					bi, bj, best = i, j, waste
+				}
+			}
+		}
+
+		u := rg.rects[bi].Union(rg.rects[bj])
+		rg.rects = append(rg.rects[:bj], rg.rects[bj+1:]...)
+		rg.rects = append(rg.rects[:bi], rg.rects[bi+1:]...)
+		rg.add(u)
+
+		if len(rg.rects) >= before {
+			// merging made things worse; give up and cover everything
+			b := rg.Bounds().Union(u)
+			rg.rects = append(rg.rects[:0], b)
+			return
+		}
+	}
+}
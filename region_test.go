@@ -0,0 +1,143 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+func Test_RegionOps(t *testing.T) {
+	var rg Region
+	rg.Add(image.Rect(0, 0, 10, 10))
+	rg.Add(image.Rect(5, 5, 15, 15))
+	if rg.Area() != 175 {
+		t.Errorf("union area = %d, want 175", rg.Area())
+	}
+
+	rg.Subtract(image.Rect(0, 0, 5, 15))
+	if rg.Area() != 125 || rg.Contains(image.Pt(4, 4)) || !rg.Contains(image.Pt(5, 0)) {
+		t.Errorf("after subtract, area = %d, rects %v", rg.Area(), rg.Rects())
+	}
+
+	rg.Intersect(image.Rect(0, 0, 10, 10))
+	if !rg.Eq(image.Rect(5, 0, 10, 10)) {
+		t.Errorf("after intersect, rects %v", rg.Rects())
+	}
+
+	// touching rectangles coalesce
+	rg.Clear()
+	rg.Add(image.Rect(0, 0, 4, 4))
+	rg.Add(image.Rect(4, 0, 8, 4))
+	rg.Add(image.Rect(0, 4, 8, 8))
+	if len(rg.Rects()) != 1 || !rg.Eq(image.Rect(0, 0, 8, 8)) {
+		t.Errorf("coalescing gave %v", rg.Rects())
+	}
+
+	// opposite corners stay separate
+	rg.Clear()
+	rg.Add(image.Rect(0, 0, 2, 2))
+	rg.Add(image.Rect(238, 318, 240, 320))
+	if len(rg.Rects()) != 2 || rg.Area() != 8 {
+		t.Errorf("corners gave %v", rg.Rects())
+	}
+}
+
+// Test_RegionRandom checks a Region against a set of the pixels it should cover.
+// With a tight budget, the Region may cover more than that, but never less.
+func Test_RegionRandom(t *testing.T) {
+	rand.Seed(4)
+	bounds := image.Rect(0, 0, 64, 64)
+	for i := 0; i < 200; i++ {
+		exact := i%2 == 0
+		rg := Region{MaxRects: 1 + rand.Intn(8)}
+		if exact {
+			rg.MaxRects = 1000
+		}
+		set := map[image.Point]bool{}
+
+		for j := 0; j < 20; j++ {
+			r := randomRect(bounds)
+			if rand.Intn(4) == 0 {
+				rg.Subtract(r)
+				forAllPix(r.Canon(), func(x, y int) { delete(set, image.Pt(x, y)) })
+			} else {
+				rg.Add(r)
+				forAllPix(r.Canon(), func(x, y int) { set[image.Pt(x, y)] = true })
+			}
+
+			if len(rg.Rects()) > rg.MaxRects {
+				t.Fatalf("%d rects, over budget of %d", len(rg.Rects()), rg.MaxRects)
+			}
+			rects := rg.Rects()
+			for a := range rects {
+				for b := a + 1; b < len(rects); b++ {
+					if rects[a].Overlaps(rects[b]) {
+						t.Fatalf("%v and %v overlap", rects[a], rects[b])
+					}
+				}
+			}
+			for pt := range set {
+				if !rg.Contains(pt) {
+					t.Fatalf("iteration %d: %v missing from region", i, pt)
+				}
+			}
+			if exact && rg.Area() != len(set) {
+				t.Fatalf("iteration %d: region area %d, want %d", i, rg.Area(), len(set))
+			}
+		}
+	}
+}
+
+// Test_DirtyRects checks that damage in opposite corners is flushed as two
+// small rectangles, not one covering the whole screen.
+func Test_DirtyRects(t *testing.T) {
+	front := NewRGB565(image.Rect(0, 0, 64, 64))
+	back := NewRGB565WithDoubleBuffer(front)
+
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	back.Fill(image.Rect(0, 0, 64, 64), red)
+	back.Flush()
+	if len(back.DirtyRects()) != 0 {
+		t.Fatalf("Flush left %v dirty", back.DirtyRects())
+	}
+
+	// sneak a change past the dirty tracking; it must not be flushed
+	back.Pix[back.PixOffset(32, 32)] = 0
+
+	back.Fill(image.Rect(0, 0, 4, 4), color.Black)
+	back.Fill(image.Rect(60, 60, 64, 64), color.Black)
+	if got := back.DirtyRects(); len(got) != 2 {
+		t.Fatalf("got dirty rects %v, want the two corners", got)
+	}
+	back.Flush()
+
+	want := front.ColorModel().Convert(red)
+	if front.At(32, 32) != want {
+		t.Error("Flush copied more than the dirty rects")
+	}
+	if front.At(0, 0) == want || front.At(63, 63) == want {
+		t.Error("Flush missed a dirty rect")
+	}
+}
+
+// Test_DirtyUntracked checks that framebuffers without a double buffer skip
+// damage tracking altogether.
+func Test_DirtyUntracked(t *testing.T) {
+	bounds := image.Rect(0, 0, 16, 16)
+	for name, dst := range map[string]interface {
+		Drawer
+		Filler
+		DirtyRects() []image.Rectangle
+	}{
+		"RGBA":     NewRGBA(image.NewRGBA(bounds)),
+		"Paletted": NewPaletted(image.NewPaletted(bounds, color.Palette{color.Black, color.White})),
+		"RGB565":   NewRGB565(bounds),
+	} {
+		dst.Set(1, 1, color.White)
+		dst.Fill(image.Rect(4, 4, 8, 8), color.White)
+		if got := dst.DirtyRects(); len(got) != 0 {
+			t.Errorf("%s: tracked %v with nothing to flush to", name, got)
+		}
+	}
+}
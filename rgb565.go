@@ -0,0 +1,245 @@
+package gfx
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+)
+
+const (
+	// rgb565Width is how many bytes a single RGB565 pixel takes up.
+	rgb565Width = 2
+)
+
+// RGB565 is an in-memory framebuffer of big endian RGB565 pixels, the format most
+// SPI display controllers expect. Pix is laid out exactly as it goes over the wire,
+// so it can be handed directly to a driver (or DMA) for transfer.
+//
+// RGB565 implements all the gfx interfaces.
+type RGB565 struct {
+	// Pix holds the image's pixels, two bytes per pixel, high byte first. The pixel
+	// at (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*2].
+	Pix []uint8
+	// Stride is the Pix stride (in bytes) between vertically adjacent pixels.
+	Stride int
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+	// ScrollFill decides what happens to the area vacated by scrolling.
+	ScrollFill ScrollFill
+
+	doubleBuf *RGB565
+	dirty     Region
+}
+
+// NewRGB565 returns a new RGB565 framebuffer with the given bounds.
+func NewRGB565(r image.Rectangle) *RGB565 {
+	return &RGB565{
+		Pix:    make([]uint8, rgb565Width*r.Dx()*r.Dy()),
+		Stride: rgb565Width * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewRGB565WithDoubleBuffer returns a new RGB565 framebuffer that buffers all
+// changes; they are copied into base when Flush is called.
+func NewRGB565WithDoubleBuffer(base *RGB565) *RGB565 {
+	buf := NewRGB565(base.Rect)
+	buf.doubleBuf = base
+	for y := base.Rect.Min.Y; y < base.Rect.Max.Y; y++ {
+		copy(buf.Pix[buf.PixOffset(base.Rect.Min.X, y):], base.Pix[base.PixOffset(base.Rect.Min.X, y):base.PixOffset(base.Rect.Max.X, y)])
+	}
+	return buf
+}
+
+func (p *RGB565) ColorModel() color.Model { return RGB565BEModel }
+
+func (p *RGB565) Bounds() image.Rectangle { return p.Rect }
+
+func (p *RGB565) At(x, y int) color.Color {
+	return p.RGB565BEAt(x, y)
+}
+
+// RGB565BEAt returns the pixel at (x, y) without going through the color.Color interface.
+func (p *RGB565) RGB565BEAt(x, y int) RGB565BE {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return 0
+	}
+	i := p.PixOffset(x, y)
+	// RGB565BE is defined as the big endian bytes loaded as a native integer.
+	return RGB565BE(binary.NativeEndian.Uint16(p.Pix[i : i+rgb565Width : i+rgb565Width]))
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *RGB565) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*rgb565Width
+}
+
+func (p *RGB565) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	p.setRGB565BE(p.PixOffset(x, y), toRGB565BE(c))
+}
+
+// SetRGB565BE sets the pixel at (x, y) without going through the color.Color interface.
+func (p *RGB565) SetRGB565BE(x, y int, c RGB565BE) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	p.setRGB565BE(p.PixOffset(x, y), c)
+}
+
+func (p *RGB565) setRGB565BE(i int, c RGB565BE) {
+	binary.NativeEndian.PutUint16(p.Pix[i:i+rgb565Width:i+rgb565Width], uint16(c))
+}
+
+// SubImage returns an image representing the portion of p visible through r.
+// The returned value shares pixels with the original, but not its double buffer:
+// changes made through it are not tracked and will not be picked up by Flush
+// unless that part of p is otherwise dirty.
+func (p *RGB565) SubImage(r image.Rectangle) image.Image {
+	r = r.Intersect(p.Rect)
+	if r.Empty() {
+		return &RGB565{}
+	}
+	i := p.PixOffset(r.Min.X, r.Min.Y)
+	return &RGB565{
+		Pix:    p.Pix[i:],
+		Stride: p.Stride,
+		Rect:   r,
+	}
+}
+
+// Fill implements gfx.Filler.
+func (p *RGB565) Fill(where image.Rectangle, c color.Color) {
+	where = p.Rect.Intersect(where)
+	if where.Empty() {
+		return
+	}
+	p.dirtyAdd(where)
+
+	var pattern [rgb565Width]byte
+	binary.NativeEndian.PutUint16(pattern[:], uint16(toRGB565BE(c)))
+
+	// fill the first row, then copy it into the rest
+	width := where.Dx() * rgb565Width
+	first := p.PixOffset(where.Min.X, where.Min.Y)
+	fillRow(p.Pix[first:first+width], pattern[:])
+	for y := where.Min.Y + 1; y < where.Max.Y; y++ {
+		i := p.PixOffset(where.Min.X, y)
+		copy(p.Pix[i:i+width:i+width], p.Pix[first:first+width])
+	}
+}
+
+// Blit implements gfx.Blitter. If src is also an *RGB565, whole rows are copied at a time.
+func (p *RGB565) Blit(src image.Image, where image.Point) {
+	destRect, sp := clipBlit(p.Rect, src.Bounds(), where)
+	if destRect.Empty() {
+		return
+	}
+	p.dirtyAdd(destRect)
+
+	if s, ok := src.(*RGB565); ok {
+		width := destRect.Dx() * rgb565Width
+		if samePix(s.Pix, p.Pix) && sp.Y < destRect.Min.Y {
+			// overlapping copy within ourselves; go bottom up
+			for y := destRect.Dy() - 1; y >= 0; y-- {
+				d, o := p.PixOffset(destRect.Min.X, destRect.Min.Y+y), s.PixOffset(sp.X, sp.Y+y)
+				copy(p.Pix[d:d+width], s.Pix[o:o+width])
+			}
+			return
+		}
+		for y := 0; y < destRect.Dy(); y++ {
+			d, o := p.PixOffset(destRect.Min.X, destRect.Min.Y+y), s.PixOffset(sp.X, sp.Y+y)
+			copy(p.Pix[d:d+width], s.Pix[o:o+width])
+		}
+		return
+	}
+
+	for y := 0; y < destRect.Dy(); y++ {
+		i := p.PixOffset(destRect.Min.X, destRect.Min.Y+y)
+		for x := 0; x < destRect.Dx(); x++ {
+			p.setRGB565BE(i, toRGB565BE(src.At(sp.X+x, sp.Y+y)))
+			i += rgb565Width
+		}
+	}
+}
+
+// Scroll implements gfx.Scroller.
+func (p *RGB565) Scroll(amount int) {
+	p.RegionScroll(p.Rect, amount)
+}
+
+// RegionScroll implements gfx.RegionScroller.
+func (p *RGB565) RegionScroll(region image.Rectangle, amount int) {
+	region = p.Rect.Intersect(region)
+	if region.Empty() || amount == 0 {
+		return
+	}
+	p.dirtyAdd(region)
+	defer p.ScrollFill.apply(p, region, image.Pt(0, amount))
+
+	scrollRows(p.Pix, p.Stride, p.PixOffset(region.Min.X, region.Min.Y), region.Dx()*rgb565Width, region.Dy(), amount)
+}
+
+// VectorScroll implements gfx.VectorScroller. Unless ScrollFill says otherwise,
+// pixels scrolled off one edge of region wrap around to the opposite edge.
+func (p *RGB565) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = p.Rect.Intersect(region)
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	p.dirtyAdd(region)
+	defer p.ScrollFill.apply(p, region, vector)
+
+	rotateRows(p.Pix, p.Stride, p.PixOffset(region.Min.X, region.Min.Y), region.Dx()*rgb565Width, region.Dy(), rgb565Width, vector)
+}
+
+// Flush implements gfx.DoubleBufferer. If p was not created with
+// NewRGB565WithDoubleBuffer, Flush does nothing.
+func (p *RGB565) Flush() {
+	if p.doubleBuf == nil {
+		return
+	}
+
+	if p.dirty.Eq(p.Rect) && p.Stride == p.doubleBuf.Stride {
+		copy(p.doubleBuf.Pix, p.Pix)
+	} else {
+		for _, r := range p.dirty.Rects() {
+			p.flush(r)
+		}
+	}
+
+	p.dirty.Clear()
+}
+
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+// Changes are only tracked if p has a double buffer.
+func (p *RGB565) DirtyRects() []image.Rectangle {
+	return p.dirty.Rects()
+}
+
+func (p *RGB565) flush(rect image.Rectangle) {
+	width := rect.Dx() * rgb565Width
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		src, dst := p.PixOffset(rect.Min.X, y), p.doubleBuf.PixOffset(rect.Min.X, y)
+		copy(p.doubleBuf.Pix[dst:dst+width:dst+width], p.Pix[src:src+width:src+width])
+	}
+}
+
+func (p *RGB565) dirtyAdd(rect image.Rectangle) {
+	if p.doubleBuf == nil {
+		return
+	}
+	p.dirty.Add(rect.Intersect(p.Rect))
+}
+
+func toRGB565BE(c color.Color) RGB565BE {
+	if native, ok := c.(RGB565BE); ok {
+		return native
+	}
+	return rgb565BEModelFunc(c).(RGB565BE)
+}
@@ -0,0 +1,143 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Blitter        = &RGB565{}
+	_ Filler         = &RGB565{}
+	_ Scroller       = &RGB565{}
+	_ RegionScroller = &RGB565{}
+	_ VectorScroller = &RGB565{}
+	_ DoubleBufferer = &RGB565{}
+)
+
+func Test_RGB565Wire(t *testing.T) {
+	p := NewRGB565(image.Rect(0, 0, 4, 4))
+	p.Set(1, 0, color.RGBA{0xFF, 0, 0, 0xFF})
+	if p.Pix[2] != 0xF8 || p.Pix[3] != 0x00 {
+		t.Errorf("red stored as % x, want f8 00", p.Pix[2:4])
+	}
+	p.Set(2, 0, color.RGBA{0, 0, 0xFF, 0xFF})
+	if p.Pix[4] != 0x00 || p.Pix[5] != 0x1F {
+		t.Errorf("blue stored as % x, want 00 1f", p.Pix[4:6])
+	}
+	if r, _, _, _ := p.At(1, 0).RGBA(); r != 0xFFFF {
+		t.Errorf("red read back as %x", r)
+	}
+}
+
+func Test_RGB565FillBlit(t *testing.T) {
+	p := NewRGB565(image.Rect(0, 0, 16, 16))
+	red := NewRGB565BE(0xFF, 0, 0)
+	p.Fill(image.Rect(-4, 2, 4, 6), red)
+
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			want := RGB565BE(0)
+			if x < 4 && y >= 2 && y < 6 {
+				want = red
+			}
+			if got := p.RGB565BEAt(x, y); got != want {
+				t.Fatalf("after Fill, pixel (%d,%d) = %x, want %x", x, y, got, want)
+			}
+		}
+	}
+
+	// blit the filled corner somewhere else, partially off screen
+	q := NewRGB565(image.Rect(0, 0, 16, 16))
+	q.Blit(p.SubImage(image.Rect(0, 2, 4, 6)), image.Pt(14, 14))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			want := RGB565BE(0)
+			if x >= 14 && y >= 14 {
+				want = red
+			}
+			if got := q.RGB565BEAt(x, y); got != want {
+				t.Fatalf("after Blit, pixel (%d,%d) = %x, want %x", x, y, got, want)
+			}
+		}
+	}
+
+	// and via the slow path
+	q.Blit(image.NewUniform(color.White), image.Pt(0, 0))
+	if got := q.RGB565BEAt(0, 0); got != NewRGB565BE(0xFF, 0xFF, 0xFF) {
+		t.Errorf("slow path Blit: got %x", got)
+	}
+
+	// a SubImage blitted onto its own buffer, overlapping up, down and sideways
+	for _, tc := range []struct {
+		sr image.Rectangle
+		at image.Point
+	}{
+		{image.Rect(0, 0, 4, 7), image.Pt(0, 2)},
+		{image.Rect(3, 5, 9, 12), image.Pt(2, 1)},
+		{image.Rect(2, 2, 10, 6), image.Pt(5, 3)},
+	} {
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				p.SetRGB565BE(x, y, RGB565BE(y*16+x+1))
+			}
+		}
+		p.Blit(p.SubImage(tc.sr), tc.at)
+		moved := tc.sr.Sub(tc.sr.Min).Add(tc.at)
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x++ {
+				from := image.Pt(x, y)
+				if from.In(moved) {
+					from = from.Sub(tc.at).Add(tc.sr.Min)
+				}
+				if got, want := p.RGB565BEAt(x, y), RGB565BE(from.Y*16+from.X+1); got != want {
+					t.Fatalf("self Blit of %v to %v: pixel (%d,%d) = %x, want %x", tc.sr, tc.at, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func Test_RGB565Scroll(t *testing.T) {
+	p := NewRGB565(image.Rect(0, 0, 3, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			p.SetRGB565BE(x, y, RGB565BE(y*3+x))
+		}
+	}
+
+	p.VectorScroll(p.Rect, image.Pt(1, 2))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			want := RGB565BE(((y+2)%3)*3 + (x+1)%3)
+			if got := p.RGB565BEAt(x, y); got != want {
+				t.Errorf("VectorScroll: pixel (%d,%d) = %d, want %d", x, y, got, want)
+			}
+		}
+	}
+
+	p.VectorScroll(p.Rect, image.Pt(-1, -2))
+	p.Scroll(1)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			if got, want := p.RGB565BEAt(x, y), RGB565BE((y+1)*3+x); got != want {
+				t.Errorf("Scroll: pixel (%d,%d) = %d, want %d", x, y, got, want)
+			}
+		}
+	}
+}
+
+func Test_RGB565DoubleBuffer(t *testing.T) {
+	front := NewRGB565(image.Rect(0, 0, 8, 8))
+	back := NewRGB565WithDoubleBuffer(front)
+
+	back.Fill(image.Rect(2, 2, 4, 4), color.White)
+	if front.RGB565BEAt(2, 2) != 0 {
+		t.Fatal("front buffer changed before Flush")
+	}
+	back.Flush()
+	if front.RGB565BEAt(2, 2) != NewRGB565BE(0xFF, 0xFF, 0xFF) || front.RGB565BEAt(4, 4) != 0 {
+		t.Fatal("Flush did not copy the dirty area")
+	}
+}
@@ -14,8 +14,10 @@ const (
 // RGBA wraps an *image.RGBA and implements all the gfx interfaces
 type RGBA struct {
 	*image.RGBA
-	doubleBuf *image.RGBA
-	dirty     image.Rectangle
+	// ScrollFill decides what happens to the area vacated by scrolling.
+	ScrollFill ScrollFill
+	doubleBuf  *image.RGBA
+	dirty      Region
 }
 
 func NewRGBA(base *image.RGBA) *RGBA {
@@ -36,34 +38,51 @@ func (rgba *RGBA) Set(x, y int, c color.Color) {
 	rgba.RGBA.Set(x, y, c)
 }
 
+// Flush implements gfx.DoubleBufferer, copying only the dirty rectangles to the
+// front buffer. Without a double buffer it just marks everything clean.
 func (rgba *RGBA) Flush() {
-	// TODO: partial updates / dirty rect
-	if rgba.doubleBuf == nil {
-		return
+	if rgba.doubleBuf != nil {
+		if rgba.dirty.Eq(rgba.RGBA.Bounds()) {
+			copy(rgba.doubleBuf.Pix, rgba.RGBA.Pix)
+		} else {
+			for _, r := range rgba.dirty.Rects() {
+				rgba.flush(r)
+			}
+		}
 	}
 
-	if rgba.dirty.Eq(rgba.RGBA.Bounds()) {
-		copy(rgba.doubleBuf.Pix, rgba.RGBA.Pix)
-	} else {
-		rgba.flush(rgba.dirty)
-	}
+	rgba.dirty.Clear()
+}
 
-	rgba.dirty = image.Rectangle{}
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+// A driver managing its own transfers can send just these, then call Flush.
+func (rgba *RGBA) DirtyRects() []image.Rectangle {
+	return rgba.dirty.Rects()
 }
 
 func (rgba *RGBA) dirtyAll() {
-	rgba.dirty = rgba.RGBA.Bounds()
+	if rgba.doubleBuf == nil {
+		return
+	}
+	rgba.dirty.Clear()
+	rgba.dirty.Add(rgba.RGBA.Bounds())
 }
 
 func (rgba *RGBA) dirtyAdd(rect image.Rectangle) {
-	rgba.dirty = rgba.dirty.Union(rect)
+	if rgba.doubleBuf == nil {
+		return
+	}
+	rgba.dirty.Add(rect.Intersect(rgba.RGBA.Bounds()))
 }
 
 // Scroll implements gfx.Scroller
 func (rgba *RGBA) Scroll(amount int) {
-	switch {
-	case amount == 0:
+	if amount == 0 {
 		return
+	}
+	defer rgba.ScrollFill.apply(rgba, rgba.Rect, image.Pt(0, amount))
+
+	switch {
 	case amount > 0:
 		rgba.dirtyAll()
 		if amount > rgba.Rect.Dy() {
@@ -88,6 +107,7 @@ func (rgba *RGBA) RegionScroll(region image.Rectangle, amount int) {
 	}
 	// if amount is positive or negative, copy lines forwards or backwards
 	rgba.dirtyAdd(region)
+	defer rgba.ScrollFill.apply(rgba, region, image.Pt(0, amount))
 
 	var start, end int
 	if amount > 0 {
@@ -109,7 +129,8 @@ func (rgba *RGBA) RegionScroll(region image.Rectangle, amount int) {
 	}
 }
 
-// VectorScroll implements gfx.VectorScroller.
+// VectorScroll implements gfx.VectorScroller. Unless ScrollFill says otherwise,
+// pixels scrolled off one edge of region wrap around to the opposite edge.
 func (rgba *RGBA) VectorScroll(region image.Rectangle, vector image.Point) {
 	region = rgba.Rect.Intersect(region)
 	if region.Empty() || vector == (image.Point{}) {
@@ -117,65 +138,10 @@ func (rgba *RGBA) VectorScroll(region image.Rectangle, vector image.Point) {
 	}
 
 	rgba.dirtyAdd(region)
-	// The below is a bit verbose (could simplify it with a few if statements inside the for-loops).
-	// It is kept verbose for the sake of performance.
-
-	// the long, confusing src = lines work like this
-	// newOffset = stride * [ (y+y_offset+height) % height ] + rgbaWidth * [ (x+x_offset+width) % width]
-	//
-	// (d + d_offset + d_total) % d_total is the majority of the magic. Increment (or decrement) d by d_offset modulo
-	// the maximum of our working dimention. The extra addition of the maximum work dimension is to take care of negative
-	// offsets.
-	var dst, src int
-	if vector.Y > 0 {
-		if vector.X >= 0 {
-			// down and (possibly) to the right
-			// start at the top work our way down, start on the left, work our way right
-			for y := range region.Dy() {
-				for x := range region.Dx() {
-					dst = rgba.PixOffset(region.Min.X+x, region.Min.Y+y)
-					src = (region.Min.Y+((y+vector.Y+region.Dy())%region.Dy()))*rgba.Stride + (region.Min.X+((x+vector.X+region.Dx())%region.Dx()))*rgbaWidth
-					copy(rgba.Pix[dst:dst+rgbaWidth:dst+rgbaWidth], rgba.Pix[src:src+rgbaWidth:src+rgbaWidth])
-				}
-			}
-		} else {
-			for y := range region.Dy() {
-				for x := range region.Dx() {
-					x = region.Dx() - 1 - x
-					dst = rgba.PixOffset(region.Min.X+x, region.Min.Y+y)
-
-					src = (region.Min.Y+((y+vector.Y+region.Dy())%region.Dy()))*rgba.Stride + (region.Min.X+((x+vector.X+region.Dx())%region.Dx()))*rgbaWidth
-					copy(rgba.Pix[dst:dst+rgbaWidth:src+rgbaWidth], rgba.Pix[src:src+rgbaWidth:src+rgbaWidth])
-				}
-			}
-		}
-	} else {
-		if vector.X >= 0 {
-			// down and (possibly) to the right
-			// start at the top work our way down, start on the left, work our way right
-
-			for y := range region.Dy() {
-				y = region.Dy() - 1 - y
-				for x := range region.Dx() {
-					dst = rgba.PixOffset(region.Min.X+x, region.Min.Y+y)
-
-					src = (region.Min.Y+((y+vector.Y+region.Dy())%region.Dy()))*rgba.Stride + (region.Min.X+((x+vector.X+region.Dx())%region.Dx()))*rgbaWidth
-					copy(rgba.Pix[dst:dst+rgbaWidth:dst+rgbaWidth], rgba.Pix[src:src+rgbaWidth:src+rgbaWidth])
-				}
-			}
-		} else {
-			for y := range region.Dy() {
-				y = region.Dy() - 1 - y
-				for x := range region.Dx() {
-					x = region.Dx() - 1 - x
-					dst := rgba.PixOffset(region.Min.X+x, region.Min.Y+y)
-
-					src := (region.Min.Y+((y+vector.Y+region.Dy())%region.Dy()))*rgba.Stride + (region.Min.X+((x+vector.X+region.Dx())%region.Dx()))*rgbaWidth
-					copy(rgba.Pix[dst:dst+rgbaWidth:dst+rgbaWidth], rgba.Pix[src:src+rgbaWidth:src+rgbaWidth])
-				}
-			}
-		}
-	}
+	defer rgba.ScrollFill.apply(rgba, region, vector)
+
+	// Rotate the region in place; see rotateRows.
+	rotateRows(rgba.Pix, rgba.Stride, rgba.PixOffset(region.Min.X, region.Min.Y), region.Dx()*rgbaWidth, region.Dy(), rgbaWidth, vector)
 }
 
 // Fill implements gfx.Filler. Whereever rgba overlaps with 'where', set those
@@ -207,10 +173,13 @@ func (rgba *RGBA) Fill(where image.Rectangle, c color.Color) {
 	}
 }
 
+// Blit implements gfx.Blitter. If src is also an *RGBA or *image.RGBA, whole rows
+// are copied at a time; src may be a SubImage of rgba itself.
 func (rgba *RGBA) Blit(src image.Image, where image.Point) {
-	srcBounds := src.Bounds()
-	destRect := image.Rect(0, 0, srcBounds.Dx(), srcBounds.Dy())
-	destRect = srcBounds.Intersect(destRect.Add(where))
+	destRect, sp := clipBlit(rgba.Rect, src.Bounds(), where)
+	if destRect.Empty() {
+		return
+	}
 
 	// fast path,
 	gfxRGBA, okGFX := src.(*RGBA)
@@ -223,27 +192,28 @@ func (rgba *RGBA) Blit(src image.Image, where image.Point) {
 			src = srcRGBA
 		}
 
-		// destRect := image.Rect(0, 0, srcBounds.Dx(), srcBounds.Dy())
-		// destRect = srcBounds.Intersect(destRect.Add(where))
-		// srcBounds := src.Bounds()
-
-		for yd, ys := destRect.Min.Y, srcBounds.Min.Y; yd < destRect.Max.Y && ys < srcBounds.Max.Y; {
-			for xd, xs := destRect.Min.X, srcBounds.Min.X; xd < destRect.Max.X && xs < srcBounds.Max.X; {
-				destOffset := rgba.PixOffset(xd, yd)
-				srcOffset := src.PixOffset(xs, ys)
-				copy(rgba.Pix[destOffset:destOffset+rgbaWidth:destOffset+rgbaWidth], src.Pix[srcOffset:srcOffset+4:srcOffset+4])
-				xd++
-				xs++
+		rgba.dirtyAdd(destRect)
+		width := destRect.Dx() * rgbaWidth
+		if samePix(src.Pix, rgba.Pix) && sp.Y < destRect.Min.Y {
+			// overlapping copy within ourselves; go bottom up
+			for y := destRect.Dy() - 1; y >= 0; y-- {
+				destOffset, srcOffset := rgba.PixOffset(destRect.Min.X, destRect.Min.Y+y), src.PixOffset(sp.X, sp.Y+y)
+				copy(rgba.Pix[destOffset:destOffset+width], src.Pix[srcOffset:srcOffset+width])
 			}
-			yd++
-			ys++
+			return
+		}
+		for y := 0; y < destRect.Dy(); y++ {
+			destOffset, srcOffset := rgba.PixOffset(destRect.Min.X, destRect.Min.Y+y), src.PixOffset(sp.X, sp.Y+y)
+			copy(rgba.Pix[destOffset:destOffset+width], src.Pix[srcOffset:srcOffset+width])
 		}
 
 		return
 	}
 
 	// slow fall back
-	blit(rgba, src, where)
+	forAllPix(destRect, func(x, y int) {
+		rgba.RGBA.Set(x, y, src.At(x-destRect.Min.X+sp.X, y-destRect.Min.Y+sp.Y))
+	})
 
 	rgba.dirtyAdd(destRect)
 }
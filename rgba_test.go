@@ -0,0 +1,84 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+func Test_RGBABlit(t *testing.T) {
+	rand.Seed(7)
+	bounds := image.Rect(0, 0, 16, 16)
+	noise := func(r image.Rectangle) *image.RGBA {
+		img := image.NewRGBA(r)
+		rand.Read(img.Pix)
+		return img
+	}
+
+	for i := 0; i < 100; i++ {
+		dst := NewRGBA(noise(bounds))
+		want := image.NewRGBA(bounds)
+		copy(want.Pix, dst.Pix)
+
+		// sources off the origin, blitted partly off every edge
+		min := image.Pt(rand.Intn(20)-10, rand.Intn(20)-10)
+		src := noise(image.Rectangle{min, min.Add(image.Pt(rand.Intn(24), rand.Intn(24)))})
+		at := image.Pt(rand.Intn(32)-12, rand.Intn(32)-12)
+
+		dst.Blit(src, at)
+		draw.Draw(want, src.Rect.Sub(src.Rect.Min).Add(at), src, src.Rect.Min, draw.Src)
+		if string(dst.Pix) != string(want.Pix) {
+			t.Fatalf("Blit of %v to %v differs", src.Rect, at)
+		}
+
+		// and blitted onto itself, overlapping either way
+		sp := image.Pt(rand.Intn(8), rand.Intn(8))
+		self := dst.SubImage(image.Rectangle{sp, sp.Add(image.Pt(8, 8))})
+		at = image.Pt(rand.Intn(8), rand.Intn(8))
+		draw.Draw(want, image.Rectangle{at, at.Add(image.Pt(8, 8))}, want, sp, draw.Src)
+		dst.Blit(self, at)
+		if string(dst.Pix) != string(want.Pix) {
+			t.Fatalf("self Blit from %v to %v differs", sp, at)
+		}
+	}
+
+	// other sources are read only where they land, even if they are huge
+	dst := NewRGBA(image.NewRGBA(bounds))
+	dst.Blit(image.NewUniform(color.White), image.Pt(-1e8, 4))
+	if dst.At(0, 3) != (color.RGBA{}) || dst.At(15, 4) != (color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}) {
+		t.Error("Blit of a Uniform missed")
+	}
+}
+
+func Test_RGBAVectorScroll(t *testing.T) {
+	rand.Seed(9)
+	bounds := image.Rect(0, 0, 12, 10)
+	for i := 0; i < 100; i++ {
+		dst := NewRGBA(image.NewRGBA(bounds))
+		rand.Read(dst.Pix)
+		before := image.NewRGBA(bounds)
+		copy(before.Pix, dst.Pix)
+
+		region := image.Rect(rand.Intn(6), rand.Intn(5), 6+rand.Intn(7), 5+rand.Intn(6))
+		vector := image.Pt(rand.Intn(30)-15, rand.Intn(30)-15)
+		dst.VectorScroll(region, vector)
+
+		// pixels in region come from vector away, wrapping around its edges;
+		// the rest are untouched
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				from := image.Pt(x, y)
+				if from.In(region) {
+					from = image.Pt(
+						region.Min.X+mod(x-region.Min.X+vector.X, region.Dx()),
+						region.Min.Y+mod(y-region.Min.Y+vector.Y, region.Dy()))
+				}
+				if dst.RGBAAt(x, y) != before.RGBAAt(from.X, from.Y) {
+					t.Fatalf("VectorScroll(%v, %v): (%d,%d) is not what was at %v", region, vector, x, y, from)
+				}
+			}
+		}
+	}
+}
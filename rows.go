@@ -0,0 +1,115 @@
+package gfx
+
+import "image"
+
+// Helpers for framebuffers that store their pixels as rows of bytes, with each
+// pixel taking up a whole number of bytes.
+
+// clipBlit works out what part of src, placed with its Min at 'at', lands inside
+// bounds. It returns the destination rectangle and the matching top-left point in src.
+func clipBlit(bounds, src image.Rectangle, at image.Point) (image.Rectangle, image.Point) {
+	dst := src.Sub(src.Min).Add(at).Intersect(bounds)
+	return dst, src.Min.Add(dst.Min.Sub(at))
+}
+
+// samePix reports whether a and b are views into the same pixels, as an image
+// and a SubImage of it are.
+func samePix(a, b []byte) bool {
+	return cap(a) > 0 && cap(b) > 0 && &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
+
+// scrollRows scrolls height rows of width bytes, the first of which starts at
+// pix[start], by amount rows. Positive amounts move the rows up. The vacated rows
+// are left as they were.
+func scrollRows(pix []byte, stride, start, width, height, amount int) {
+	if amount == 0 || height <= 0 {
+		return
+	}
+
+	if amount >= height || -amount >= height {
+		return
+	}
+
+	if amount > 0 {
+		for y := 0; y < height-amount; y++ {
+			dst := start + y*stride
+			src := dst + amount*stride
+			copy(pix[dst:dst+width:dst+width], pix[src:src+width:src+width])
+		}
+		return
+	}
+
+	for y := height - 1; y >= -amount; y-- {
+		dst := start + y*stride
+		src := dst + amount*stride
+		copy(pix[dst:dst+width:dst+width], pix[src:src+width:src+width])
+	}
+}
+
+// rotateRows wraps a block of rows (as described for scrollRows) around by vector,
+// such that afterwards the pixel at (x, y) holds what was at (x+vector.X, y+vector.Y),
+// modulo the size of the block. pixWidth is how many bytes make up a pixel.
+//
+// Rotation is done in place by reversals, so no scratch memory is needed.
+func rotateRows(pix []byte, stride, start, width, height, pixWidth int, vector image.Point) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	if k := mod(vector.X, width/pixWidth) * pixWidth; k != 0 {
+		for y := 0; y < height; y++ {
+			row := pix[start+y*stride : start+y*stride+width]
+			reverseBytes(row[:k])
+			reverseBytes(row[k:])
+			reverseBytes(row)
+		}
+	}
+
+	if k := mod(vector.Y, height); k != 0 {
+		reverseRows(pix, stride, start, width, 0, k)
+		reverseRows(pix, stride, start, width, k, height)
+		reverseRows(pix, stride, start, width, 0, height)
+	}
+}
+
+// reverseRows reverses the order of rows [from, to) of a block.
+func reverseRows(pix []byte, stride, start, width, from, to int) {
+	for i, j := from, to-1; i < j; i, j = i+1, j-1 {
+		a := pix[start+i*stride : start+i*stride+width]
+		b := pix[start+j*stride : start+j*stride+width]
+		for n := range a {
+			a[n], b[n] = b[n], a[n]
+		}
+	}
+}
+
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+// fillRow repeats pattern over row. The length of row must be a multiple of the
+// length of pattern.
+func fillRow(row, pattern []byte) {
+	if len(row) == 0 {
+		return
+	}
+	n := copy(row, pattern)
+	// double the filled section each time around
+	for n < len(row) {
+		n += copy(row[n:], row[:n])
+	}
+}
+
+// mod is like % but always returns a value in [0, m).
+func mod(a, m int) int {
+	if m == 0 {
+		return 0
+	}
+	a %= m
+	if a < 0 {
+		a += m
+	}
+	return a
+}
@@ -8,9 +8,18 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"slices"
 	"testing"
 )
 
+// interface checks
+var (
+	_ Blitter        = &SoftScreen{}
+	_ Filler         = &SoftScreen{}
+	_ RegionScroller = &SoftScreen{}
+	_ VectorScroller = &SoftScreen{}
+)
+
 func Test_SoftScreen(t *testing.T) {
 	rand.Seed(0)
 	screen := NewSoftScreen(image.Rect(0, 0, 8, 16), image.Rect(0, 0, 240, 128), image.Rect(0, 0, 240, 128))
@@ -58,3 +67,58 @@ func save(fname string, img image.Image) {
 	png.Encode(fh, img)
 	fh.Close()
 }
+
+// Test_SoftScreenFast checks SoftScreenOf's native Fill, Blit and scrolling
+// against the generic software versions, with a viewport that wraps.
+func Test_SoftScreenFast(t *testing.T) {
+	rand.Seed(14)
+	canvas := image.Rect(0, 0, 24, 20)
+	newScreen := func() *SoftScreenOf[RGB565BE] {
+		s := NewSoftScreenOf[RGB565BE](image.Rect(0, 0, 8, 8), image.Rect(0, 0, 16, 12), canvas)
+		s.Convert = RGB565BEModel
+		s.SetViewport(image.Pt(17, 13))
+		return s
+	}
+	fast, slow := newScreen(), newScreen()
+	other := newScreen()
+	other.SetViewport(image.Pt(3, 5))
+	for i := range other.Pix {
+		other.Pix[i] = RGB565BE(rand.Intn(0x10000))
+	}
+
+	for i := 0; i < 500; i++ {
+		region := randomRect(fast.Viewport.Inset(-4))
+		switch op := rand.Intn(5); op {
+		case 0:
+			c := randomColor()
+			fast.Fill(region, c)
+			fill(slow, region, c)
+		case 1:
+			other.Viewport = region
+			at := image.Pt(rand.Intn(40)-8, rand.Intn(40)-8)
+			fast.Blit(other, at)
+			blit(slow, other, at)
+		case 2:
+			// blitting from itself has to cope with overlap
+			at := fast.Viewport.Min.Add(image.Pt(rand.Intn(9)-4, rand.Intn(9)-4))
+			fast.Blit(fast, at)
+			snapshot := *slow
+			snapshot.Pix = slices.Clone(slow.Pix)
+			blit(slow, &snapshot, at)
+		case 3:
+			amount := rand.Intn(9) - 4
+			fast.RegionScroll(region, amount)
+			scroll(slow, region.Intersect(slow.Viewport), amount)
+		case 4:
+			vector := image.Pt(rand.Intn(9)-4, rand.Intn(9)-4)
+			fast.VectorScroll(region, vector)
+			VectorScroll(plainDrawer{slow}, region, vector)
+		}
+
+		for j := range fast.Pix {
+			if fast.Pix[j] != slow.Pix[j] {
+				t.Fatalf("iteration %d: pixel %d differs", i, j)
+			}
+		}
+	}
+}
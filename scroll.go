@@ -0,0 +1,216 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// ScrollFill decides what becomes of the area a scroll vacates. The zero value
+// leaves it alone: Scroll and RegionScroll leave stale pixels behind, and
+// VectorScroll wraps what scrolled off the opposite edge into it.
+//
+// Framebuffers in this package, SoftScreenOf and the scroll adapters all have a
+// ScrollFill field which they apply after every Scroll, RegionScroll and VectorScroll.
+type ScrollFill struct {
+	// Color, if not nil, is filled into the vacated area.
+	Color color.Color
+	// Expose, if not nil, is called with each vacated rectangle once Color (if
+	// any) has been filled in, so that the newly exposed strip can be rendered.
+	Expose func(vacated image.Rectangle)
+}
+
+// apply fills and exposes the area left behind in region when its contents are
+// scrolled by vector. Color is only used if f is not nil.
+func (sf ScrollFill) apply(f Filler, region image.Rectangle, vector image.Point) {
+	if sf.Color == nil && sf.Expose == nil {
+		return
+	}
+	for _, r := range vacated(region, vector) {
+		if r.Empty() {
+			continue
+		}
+		if sf.Color != nil && f != nil {
+			f.Fill(r, sf.Color)
+		}
+		if sf.Expose != nil {
+			sf.Expose(r)
+		}
+	}
+}
+
+// vacated returns the parts of region which, after its contents are scrolled by
+// vector, no longer hold anything that was in region. The first rectangle spans
+// full rows and the second the remaining columns, so the two do not overlap.
+func vacated(region image.Rectangle, vector image.Point) [2]image.Rectangle {
+	var rows, cols image.Rectangle
+	rest := region
+
+	switch {
+	case vector.Y > 0:
+		rows = image.Rect(region.Min.X, region.Max.Y-vector.Y, region.Max.X, region.Max.Y)
+		rest.Max.Y = rows.Min.Y
+	case vector.Y < 0:
+		rows = image.Rect(region.Min.X, region.Min.Y, region.Max.X, region.Min.Y-vector.Y)
+		rest.Min.Y = rows.Max.Y
+	}
+
+	switch {
+	case vector.X > 0:
+		cols = image.Rect(rest.Max.X-vector.X, rest.Min.Y, rest.Max.X, rest.Max.Y)
+	case vector.X < 0:
+		cols = image.Rect(rest.Min.X, rest.Min.Y, rest.Min.X-vector.X, rest.Max.Y)
+	}
+
+	return [2]image.Rectangle{rows.Intersect(region), cols.Intersect(rest)}
+}
+
+// ScrollAdapter wraps a Drawer and gives it whichever of Scroller, RegionScroller
+// and VectorScroller it is missing. Methods the Drawer already has are used as is;
+// the rest are built on top of VectorScroll, which falls back to the software
+// VectorScroll if the Drawer has none.
+//
+// ScrollAdapter also passes Fill, Blit and Flush through to the Drawer, so wrapping
+// does not lose access to any faster paths it has.
+type ScrollAdapter struct {
+	Drawer
+	// ScrollFill is applied after scrolling, on top of whatever the Drawer does.
+	ScrollFill ScrollFill
+}
+
+// NewScrollAdapter returns a ScrollAdapter for d.
+func NewScrollAdapter(d Drawer) *ScrollAdapter {
+	return &ScrollAdapter{Drawer: d}
+}
+
+// Scroll implements gfx.Scroller.
+func (s *ScrollAdapter) Scroll(amount int) {
+	if scroller, ok := s.Drawer.(Scroller); ok {
+		scroller.Scroll(amount)
+		s.ScrollFill.apply(s, s.Bounds(), image.Pt(0, amount))
+		return
+	}
+	s.RegionScroll(s.Bounds(), amount)
+}
+
+// RegionScroll implements gfx.RegionScroller.
+func (s *ScrollAdapter) RegionScroll(region image.Rectangle, amount int) {
+	if scroller, ok := s.Drawer.(RegionScroller); ok {
+		scroller.RegionScroll(region, amount)
+		s.ScrollFill.apply(s, region.Intersect(s.Bounds()), image.Pt(0, amount))
+		return
+	}
+	s.VectorScroll(region, image.Pt(0, amount))
+}
+
+// VectorScroll implements gfx.VectorScroller.
+func (s *ScrollAdapter) VectorScroll(region image.Rectangle, vector image.Point) {
+	if scroller, ok := s.Drawer.(VectorScroller); ok {
+		scroller.VectorScroll(region, vector)
+	} else {
+		VectorScroll(s.Drawer, region, vector)
+	}
+	s.ScrollFill.apply(s, region.Intersect(s.Bounds()), vector)
+}
+
+// Fill implements gfx.Filler.
+func (s *ScrollAdapter) Fill(r image.Rectangle, c color.Color) {
+	Fill(s.Drawer, r, c)
+}
+
+// Blit implements gfx.Blitter.
+func (s *ScrollAdapter) Blit(src image.Image, at image.Point) {
+	Blit(s.Drawer, src, at)
+}
+
+// Flush implements gfx.DoubleBufferer. It does nothing if the Drawer is not one.
+func (s *ScrollAdapter) Flush() {
+	if db, ok := s.Drawer.(DoubleBufferer); ok {
+		db.Flush()
+	}
+}
+
+// VectorScrollAdapter builds Scroller and RegionScroller on top of a bare
+// VectorScroller, such as a display driver with hardware scrolling. Rect is
+// what Scroll scrolls and what RegionScroll regions are clipped to.
+//
+// ScrollFill.Color is only used if the VectorScroller is also a Filler.
+type VectorScrollAdapter struct {
+	VectorScroller
+	Rect image.Rectangle
+	// ScrollFill is applied after scrolling, on top of whatever the VectorScroller does.
+	ScrollFill ScrollFill
+}
+
+// NewVectorScrollAdapter returns a VectorScrollAdapter for vs, covering bounds.
+func NewVectorScrollAdapter(vs VectorScroller, bounds image.Rectangle) *VectorScrollAdapter {
+	return &VectorScrollAdapter{
+		VectorScroller: vs,
+		Rect:           bounds,
+	}
+}
+
+func (v *VectorScrollAdapter) Bounds() image.Rectangle {
+	return v.Rect
+}
+
+// Scroll implements gfx.Scroller.
+func (v *VectorScrollAdapter) Scroll(amount int) {
+	v.RegionScroll(v.Rect, amount)
+}
+
+// RegionScroll implements gfx.RegionScroller.
+func (v *VectorScrollAdapter) RegionScroll(region image.Rectangle, amount int) {
+	region = region.Intersect(v.Rect)
+	if region.Empty() || amount == 0 {
+		return
+	}
+	v.VectorScroller.VectorScroll(region, image.Pt(0, amount))
+	f, _ := v.VectorScroller.(Filler)
+	v.ScrollFill.apply(f, region, image.Pt(0, amount))
+}
+
+// VectorScroll implements gfx.VectorScroller.
+func (v *VectorScrollAdapter) VectorScroll(region image.Rectangle, vector image.Point) {
+	v.VectorScroller.VectorScroll(region, vector)
+	f, _ := v.VectorScroller.(Filler)
+	v.ScrollFill.apply(f, region.Intersect(v.Rect), vector)
+}
+
+// VectorScroll is a software implementation of gfx.VectorScroller for any Drawer.
+// Pixels scrolled off one edge of region wrap around to the opposite edge.
+//
+// It works in place, swapping pixels via At and Set, so it needs no extra memory
+// but relies on dst returning from At exactly the colors given to Set.
+func VectorScroll(dst Drawer, region image.Rectangle, vector image.Point) {
+	region = region.Intersect(dst.Bounds())
+	if region.Empty() {
+		return
+	}
+
+	// rotating by k is reversing the first k, reversing the rest, then reversing the lot
+	if k := mod(vector.X, region.Dx()); k != 0 {
+		for y := region.Min.Y; y < region.Max.Y; y++ {
+			reversePixels(dst, image.Pt(region.Min.X, y), image.Pt(region.Min.X+k-1, y), image.Pt(1, 0))
+			reversePixels(dst, image.Pt(region.Min.X+k, y), image.Pt(region.Max.X-1, y), image.Pt(1, 0))
+			reversePixels(dst, image.Pt(region.Min.X, y), image.Pt(region.Max.X-1, y), image.Pt(1, 0))
+		}
+	}
+	if k := mod(vector.Y, region.Dy()); k != 0 {
+		for x := region.Min.X; x < region.Max.X; x++ {
+			reversePixels(dst, image.Pt(x, region.Min.Y), image.Pt(x, region.Min.Y+k-1), image.Pt(0, 1))
+			reversePixels(dst, image.Pt(x, region.Min.Y+k), image.Pt(x, region.Max.Y-1), image.Pt(0, 1))
+			reversePixels(dst, image.Pt(x, region.Min.Y), image.Pt(x, region.Max.Y-1), image.Pt(0, 1))
+		}
+	}
+}
+
+// reversePixels reverses the run of pixels from first to last (inclusive), which
+// are step apart.
+func reversePixels(dst Drawer, first, last, step image.Point) {
+	for first.X*step.X+first.Y*step.Y < last.X*step.X+last.Y*step.Y {
+		a, b := dst.At(first.X, first.Y), dst.At(last.X, last.Y)
+		dst.Set(first.X, first.Y, b)
+		dst.Set(last.X, last.Y, a)
+		first, last = first.Add(step), last.Sub(step)
+	}
+}
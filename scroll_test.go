@@ -0,0 +1,125 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Scroller       = &ScrollAdapter{}
+	_ RegionScroller = &ScrollAdapter{}
+	_ VectorScroller = &ScrollAdapter{}
+	_ Filler         = &ScrollAdapter{}
+	_ Blitter        = &ScrollAdapter{}
+	_ DoubleBufferer = &ScrollAdapter{}
+	_ Scroller       = &VectorScrollAdapter{}
+	_ RegionScroller = &VectorScrollAdapter{}
+)
+
+// Test_VectorScroll checks the software VectorScroll against RGB565's native one.
+func Test_VectorScroll(t *testing.T) {
+	rand.Seed(3)
+	for i := 0; i < 50; i++ {
+		bounds := image.Rect(0, 0, 1+rand.Intn(20), 1+rand.Intn(20))
+		native := NewRGB565(bounds)
+		soft := NewScrollAdapter(plainDrawer{NewRGB565(bounds)})
+		rand.Read(native.Pix)
+		soft.Blit(native, bounds.Min)
+
+		region := randomRect(bounds)
+		vector := image.Pt(rand.Intn(41)-20, rand.Intn(41)-20)
+		native.VectorScroll(region, vector)
+		soft.VectorScroll(region, vector)
+
+		forAllPix(bounds, func(x, y int) {
+			if native.At(x, y) != soft.At(x, y) {
+				t.Fatalf("iteration %d: scrolling %v by %v, pixel (%d,%d) differs", i, region, vector, x, y)
+			}
+		})
+	}
+}
+
+// recordingScroller remembers the last VectorScroll call.
+type recordingScroller struct {
+	region image.Rectangle
+	vector image.Point
+}
+
+func (r *recordingScroller) VectorScroll(region image.Rectangle, vector image.Point) {
+	r.region, r.vector = region, vector
+}
+
+func Test_VectorScrollAdapter(t *testing.T) {
+	rec := &recordingScroller{}
+	adapter := NewVectorScrollAdapter(rec, image.Rect(0, 0, 240, 320))
+
+	adapter.Scroll(-8)
+	if rec.region != adapter.Rect || rec.vector != image.Pt(0, -8) {
+		t.Errorf("Scroll became VectorScroll(%v, %v)", rec.region, rec.vector)
+	}
+
+	adapter.RegionScroll(image.Rect(0, 300, 240, 400), 16)
+	if rec.region != image.Rect(0, 300, 240, 320) || rec.vector != image.Pt(0, 16) {
+		t.Errorf("RegionScroll became VectorScroll(%v, %v)", rec.region, rec.vector)
+	}
+}
+
+func Test_ScrollFill(t *testing.T) {
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	bounds := image.Rect(0, 0, 8, 8)
+
+	targets := map[string]Drawer{
+		"RGBA":       NewRGBA(image.NewRGBA(bounds)),
+		"RGB565":     NewRGB565(bounds),
+		"Paletted":   NewPaletted(image.NewPaletted(bounds, color.Palette{color.Black, red})),
+		"SoftScreen": NewSoftScreen(image.Rect(0, 0, 8, 8), bounds, image.Rect(0, 0, 8, 16)),
+		"adapter":    NewScrollAdapter(plainDrawer{image.NewRGBA(bounds)}),
+	}
+
+	for name, dst := range targets {
+		var exposed []image.Rectangle
+		sf := ScrollFill{
+			Color:  red,
+			Expose: func(r image.Rectangle) { exposed = append(exposed, r) },
+		}
+		switch dst := dst.(type) {
+		case *RGBA:
+			dst.ScrollFill = sf
+		case *RGB565:
+			dst.ScrollFill = sf
+		case *Paletted:
+			dst.ScrollFill = sf
+		case *SoftScreen:
+			dst.ScrollFill = sf
+		case *ScrollAdapter:
+			dst.ScrollFill = sf
+		}
+		want := dst.ColorModel().Convert(red)
+
+		dst.(Scroller).Scroll(2)
+		b := dst.Bounds()
+		if len(exposed) != 1 || exposed[0] != image.Rect(b.Min.X, b.Max.Y-2, b.Max.X, b.Max.Y) {
+			t.Errorf("%s: Scroll exposed %v", name, exposed)
+		}
+		if dst.At(b.Min.X, b.Max.Y-2) != want || dst.At(b.Max.X-1, b.Max.Y-1) != want || dst.At(b.Min.X, b.Max.Y-3) == want {
+			t.Errorf("%s: Scroll did not fill the vacated rows", name)
+		}
+
+		vs, ok := dst.(VectorScroller)
+		if !ok {
+			continue
+		}
+		// relative to b.Min, since scrolling a SoftScreen pans its viewport
+		exposed = nil
+		vs.VectorScroll(image.Rect(0, 0, 4, 4).Add(b.Min), image.Pt(-1, -1))
+		if len(exposed) != 2 || exposed[0] != image.Rect(0, 0, 4, 1).Add(b.Min) || exposed[1] != image.Rect(0, 1, 1, 4).Add(b.Min) {
+			t.Errorf("%s: VectorScroll exposed %v", name, exposed)
+		}
+		if dst.At(b.Min.X, b.Min.Y+3) != want || dst.At(b.Min.X+3, b.Min.Y) != want || dst.At(b.Min.X+1, b.Min.Y+1) == want {
+			t.Errorf("%s: VectorScroll did not fill the vacated area", name)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package shape
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/sparques/gfx"
+)
+
+// LineAA draws an anti-aliased line from p0 to p1 using Xiaolin Wu's
+// algorithm: each step along the line shades the two pixels straddling it in
+// proportion to how near they are, and the ends are shaded by how much of
+// their pixel they cover. Pixels are blended over dst with gfx.Blend.
+func LineAA(dst gfx.Drawer, p0, p1 Point, c color.Color) {
+	steep := math.Abs(p1.Y-p0.Y) > math.Abs(p1.X-p0.X)
+	if steep {
+		p0, p1 = Pt(p0.Y, p0.X), Pt(p1.Y, p1.X)
+	}
+	if p0.X > p1.X {
+		p0, p1 = p1, p0
+	}
+
+	b := dst.Bounds()
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		gfx.Blend(dst, x, y, c, uint8(coverage*0xFF+0.5))
+	}
+
+	d := p1.Sub(p0)
+	gradient := 1.0
+	if d.X != 0 {
+		gradient = d.Y / d.X
+	}
+
+	// the ends cover only part of their pixels along the line
+	endpoint := func(p Point, gap float64) (int, float64) {
+		x := math.Round(p.X)
+		y := p.Y + gradient*(x-p.X)
+		fy := math.Floor(y)
+		plot(int(x), int(fy), (1-(y-fy))*gap)
+		plot(int(x), int(fy)+1, (y-fy)*gap)
+		return int(x), y
+	}
+	x0, y := endpoint(p0, 1-frac(p0.X+0.5))
+	x1, _ := endpoint(p1, frac(p1.X+0.5))
+
+	// the run between the ends, cut down to dst's bounds along the major axis
+	from, to := x0+1, x1-1
+	if steep {
+		from, to = max(from, b.Min.Y), min(to, b.Max.Y-1)
+	} else {
+		from, to = max(from, b.Min.X), min(to, b.Max.X-1)
+	}
+	y += gradient * float64(from-x0)
+	for x := from; x <= to; x++ {
+		fy := math.Floor(y)
+		plot(x, int(fy), 1-(y-fy))
+		plot(x, int(fy)+1, y-fy)
+		y += gradient
+	}
+}
+
+// CircleAA draws the anti-aliased outline of a circle.
+func CircleAA(dst gfx.Drawer, center Point, radius float64, c color.Color) {
+	EllipseAA(dst, center, radius, radius, c)
+}
+
+// EllipseAA draws the anti-aliased outline of an axis-aligned ellipse with
+// horizontal radius rx and vertical radius ry. Like LineAA, it shades the two
+// pixels straddling the curve at each step: stepping across columns where the
+// curve is flatter than 45 degrees, and down rows where it is steeper.
+func EllipseAA(dst gfx.Drawer, center Point, rx, ry float64, c color.Color) {
+	if rx <= 0 || ry <= 0 {
+		return
+	}
+	b := dst.Bounds()
+
+	// the curve is at 45 degrees where x is rx²/√(rx²+ry²), and y likewise
+	diag := math.Hypot(rx, ry)
+	xTurn, yTurn := rx*rx/diag, ry*ry/diag
+
+	// along columns, with the curve above and below the center
+	for x := max(int(math.Ceil(center.X-xTurn)), b.Min.X); x <= min(int(math.Floor(center.X+xTurn)), b.Max.X-1); x++ {
+		dx := (float64(x) - center.X) / rx
+		h := ry * math.Sqrt(max(1-dx*dx, 0))
+		for _, y := range [2]float64{center.Y - h, center.Y + h} {
+			fy := math.Floor(y)
+			gfx.Blend(dst, x, int(fy), c, uint8((1-(y-fy))*0xFF+0.5))
+			gfx.Blend(dst, x, int(fy)+1, c, uint8((y-fy)*0xFF+0.5))
+		}
+	}
+
+	// along rows, with the curve left and right of the center, leaving the
+	// columns already drawn
+	for y := max(int(math.Ceil(center.Y-yTurn)), b.Min.Y); y <= min(int(math.Floor(center.Y+yTurn)), b.Max.Y-1); y++ {
+		dy := (float64(y) - center.Y) / ry
+		w := rx * math.Sqrt(max(1-dy*dy, 0))
+		for _, x := range [2]float64{center.X - w, center.X + w} {
+			fx := math.Floor(x)
+			for i, coverage := range [2]float64{1 - (x - fx), x - fx} {
+				if px := fx + float64(i); math.Abs(px-center.X) > xTurn {
+					gfx.Blend(dst, int(px), y, c, uint8(coverage*0xFF+0.5))
+				}
+			}
+		}
+	}
+}
+
+// frac returns the fractional part of v, in [0, 1).
+func frac(v float64) float64 {
+	return v - math.Floor(v)
+}
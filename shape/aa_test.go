@@ -0,0 +1,98 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+// coverage draws with f in white on a black w by h image and returns the
+// coverage of each pixel, as the red channel.
+func coverage(w, h int, f func(dst *image.RGBA)) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	FillRect(dst, dst.Bounds(), color.Black)
+	f(dst)
+	return dst
+}
+
+func Test_LineAA(t *testing.T) {
+	// on pixel centers, a horizontal line is solid with half covered ends
+	dst := coverage(12, 6, func(dst *image.RGBA) { LineAA(dst, Pt(2, 3), Pt(10, 3), color.White) })
+	for x := 0; x < 12; x++ {
+		want := map[int]uint8{2: 0x80, 10: 0x80}[x]
+		if x > 2 && x < 10 {
+			want = 0xFF
+		}
+		if got := dst.RGBAAt(x, 3).R; got != want || dst.RGBAAt(x, 4).R != 0 || dst.RGBAAt(x, 2).R != 0 {
+			t.Errorf("pixel %d is %d, want %d", x, got, want)
+		}
+	}
+
+	// between rows, it is shared between them
+	dst = coverage(12, 6, func(dst *image.RGBA) { LineAA(dst, Pt(0, 2.5), Pt(11, 2.5), color.White) })
+	if a, b := dst.RGBAAt(5, 2).R, dst.RGBAAt(5, 3).R; a != 0x80 || b != 0x80 {
+		t.Errorf("split line is %d and %d", a, b)
+	}
+
+	// each column of a shallow line adds up to one pixel, and each row of a
+	// steep one
+	for _, steep := range []bool{false, true} {
+		p0, p1 := Pt(2, 2.3), Pt(20, 9.7)
+		if steep {
+			p0, p1 = Pt(p0.Y, p0.X), Pt(p1.Y, p1.X)
+		}
+		dst = coverage(24, 24, func(dst *image.RGBA) { LineAA(dst, p0, p1, color.White) })
+		for i := 3; i < 20; i++ {
+			var sum int
+			for j := 0; j < 24; j++ {
+				if steep {
+					sum += int(dst.RGBAAt(j, i).R)
+				} else {
+					sum += int(dst.RGBAAt(i, j).R)
+				}
+			}
+			if sum < 0xFE || sum > 0x100 {
+				t.Errorf("steep %v: line %d sums to %d", steep, i, sum)
+			}
+		}
+	}
+
+	// clipped, the line is part of the same line drawn without clipping
+	p0, p1 := Pt(-40, -13.2), Pt(50, 30.1)
+	clipped := coverage(16, 16, func(dst *image.RGBA) { LineAA(dst, p0, p1, color.White) })
+	whole := image.NewRGBA(image.Rect(-64, -64, 64, 64))
+	FillRect(whole, whole.Bounds(), color.Black)
+	LineAA(whole, p0, p1, color.White)
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if clipped.RGBAAt(x, y) != whole.RGBAAt(x, y) {
+				t.Fatalf("clipped line differs at (%d,%d)", x, y)
+			}
+		}
+	}
+}
+
+func Test_CircleAA(t *testing.T) {
+	dst := coverage(33, 33, func(dst *image.RGBA) { CircleAA(dst, Pt(16, 16), 10, color.White) })
+	cov := func(x, y int) uint8 { return dst.RGBAAt(x, y).R }
+	for y := 0; y < 33; y++ {
+		for x := 0; x < 33; x++ {
+			if c := cov(x, y); c != cov(32-x, y) || c != cov(x, 32-y) || c != cov(y, x) {
+				t.Fatalf("asymmetric at (%d,%d)", x, y)
+			}
+			if d := math.Hypot(float64(x-16), float64(y-16)); cov(x, y) != 0 && (d < 8.5 || d > 11.5) {
+				t.Fatalf("(%d,%d) is %d, %.1f from the center", x, y, cov(x, y), d)
+			}
+		}
+	}
+	if cov(16, 6) != 0xFF || cov(26, 16) != 0xFF || cov(16, 5) != 0 {
+		t.Error("circle misses its extremes")
+	}
+
+	// a wide ellipse reaches its radii
+	dst = coverage(40, 20, func(dst *image.RGBA) { EllipseAA(dst, Pt(20, 10), 15, 5, color.White) })
+	if cov(35, 10) != 0xFF || cov(5, 10) != 0xFF || cov(20, 5) != 0xFF || cov(20, 15) != 0xFF {
+		t.Error("ellipse misses its extremes")
+	}
+}
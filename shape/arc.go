@@ -0,0 +1,85 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/sparques/gfx"
+)
+
+// Arc draws the part of Circle's outline from angle start to end. Angles are in
+// radians, counterclockwise from the positive x axis as seen on screen, and
+// the arc runs counterclockwise from start to end.
+func Arc(dst gfx.Drawer, center image.Point, radius int, start, end float64, c color.Color) {
+	if radius < 0 {
+		return
+	}
+	in := arcTest(start, end)
+	q := newQuadrant(radius, radius)
+	b := dst.Bounds()
+	first, last := clipSteps(center.Y, 1, b.Min.Y, b.Max.Y)
+	for y := max(first, -radius); y <= min(last, radius); y++ {
+		lo, hi := q.row(abs(y))
+		// the run on the right, then its mirror on the left; points on the
+		// vertical axis are shared by both and drawn once
+		from, to := max(lo, b.Min.X-center.X), min(hi, b.Max.X-1-center.X)
+		for x := from; x <= to; x++ {
+			if in(x, y) {
+				dst.Set(center.X+x, center.Y+y, c)
+			}
+		}
+		from, to = max(lo, 1, center.X-b.Max.X+1), min(hi, center.X-b.Min.X)
+		for x := from; x <= to; x++ {
+			if in(-x, y) {
+				dst.Set(center.X-x, center.Y+y, c)
+			}
+		}
+	}
+}
+
+// Pie fills the slice of FillCircle from angle start to end, measured as in
+// Arc.
+func Pie(dst gfx.Drawer, center image.Point, radius int, start, end float64, c color.Color) {
+	if radius < 0 {
+		return
+	}
+	in := arcTest(start, end)
+	q := newQuadrant(radius, radius)
+	b := dst.Bounds()
+	first, last := clipSteps(center.Y, 1, b.Min.Y, b.Max.Y)
+	for y := max(first, -radius); y <= min(last, radius); y++ {
+		// draw each run of pixels inside the slice as one span
+		_, w := q.row(abs(y))
+		from, to := max(-w, b.Min.X-center.X), min(w, b.Max.X-1-center.X)
+		run := from
+		for x := from; x <= to+1; x++ {
+			if x <= to && in(x, y) {
+				continue
+			}
+			if x > run {
+				HLine(dst, center.X+run, center.X+x-1, center.Y+y, c)
+			}
+			run = x + 1
+		}
+	}
+}
+
+// arcTest returns a function reporting whether the pixel offset x, y from the
+// center lies within the arc from start to end. The center itself always does.
+func arcTest(start, end float64) func(x, y int) bool {
+	sweep := end - start
+	if sweep >= 2*math.Pi || sweep <= -2*math.Pi {
+		return func(x, y int) bool { return true }
+	}
+	sweep = math.Mod(sweep+2*math.Pi, 2*math.Pi)
+	return func(x, y int) bool {
+		if x == 0 && y == 0 {
+			return true
+		}
+		// y grows downwards, so counterclockwise is towards -y
+		a := math.Atan2(float64(-y), float64(x)) - start
+		a = math.Mod(math.Mod(a, 2*math.Pi)+2*math.Pi, 2*math.Pi)
+		return a <= sweep
+	}
+}
@@ -0,0 +1,84 @@
+package shape
+
+import (
+	"image/color"
+
+	"github.com/sparques/gfx"
+)
+
+// FlattenQuad appends to pts the ends of lines approximating the quadratic
+// Bezier curve from p0 to p2 with control point c, leaving out p0. The curve
+// is split in half until each piece is within tolerance pixels of a straight
+// line, so gentle curves take few lines and tight ones many. It is how a Path
+// flattens its curves.
+func FlattenQuad(pts []Point, p0, c, p2 Point, tolerance float64) []Point {
+	return flattenQuad(pts, p0, c, p2, tolerance, 0)
+}
+
+// FlattenCubic is FlattenQuad for the cubic Bezier curve from p0 to p3 with
+// control points c1 and c2.
+func FlattenCubic(pts []Point, p0, c1, c2, p3 Point, tolerance float64) []Point {
+	return flattenCubic(pts, p0, c1, c2, p3, tolerance, 0)
+}
+
+// Quad draws a one pixel wide quadratic Bezier curve from p0 to p2 with control
+// point c. To stroke it wider or fill it, use a Path.
+func Quad(dst gfx.Drawer, p0, c, p2 Point, col color.Color) {
+	drawFlat(dst, FlattenQuad([]Point{p0}, p0, c, p2, DefaultTolerance), col)
+}
+
+// Cubic draws a one pixel wide cubic Bezier curve from p0 to p3 with control
+// points c1 and c2. To stroke it wider or fill it, use a Path.
+func Cubic(dst gfx.Drawer, p0, c1, c2, p3 Point, col color.Color) {
+	drawFlat(dst, FlattenCubic([]Point{p0}, p0, c1, c2, p3, DefaultTolerance), col)
+}
+
+// drawFlat draws the flattened curve pts as Lines between the pixels its
+// points are in.
+func drawFlat(dst gfx.Drawer, pts []Point, c color.Color) {
+	from := pts[0].Round()
+	Line(dst, from, from, c)
+	for _, p := range pts[1:] {
+		if to := p.Round(); to != from {
+			Line(dst, from, to, c)
+			from = to
+		}
+	}
+}
+
+// CatmullRom returns the cubic Bezier curves of a uniform Catmull-Rom spline
+// through pts: a smooth curve passing through every point, such as for a line
+// chart of samples. Each curve is its start, two control points and its end,
+// and runs between neighbouring points; the ends of the spline are treated as
+// if their points were repeated.
+func CatmullRom(pts []Point) [][4]Point {
+	if len(pts) < 2 {
+		return nil
+	}
+	at := func(i int) Point {
+		return pts[min(max(i, 0), len(pts)-1)]
+	}
+	curves := make([][4]Point, 0, len(pts)-1)
+	for i := 0; i+1 < len(pts); i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+		curves = append(curves, [4]Point{
+			p1,
+			p1.Add(p2.Sub(p0).Mul(1.0 / 6)),
+			p2.Sub(p3.Sub(p1).Mul(1.0 / 6)),
+			p2,
+		})
+	}
+	return curves
+}
+
+// CatmullRom adds a Catmull-Rom spline through pts to the path, continuing the
+// current subpath with a line to the first point if there is one.
+func (p *Path) CatmullRom(pts []Point) {
+	if len(pts) == 0 {
+		return
+	}
+	p.LineTo(pts[0])
+	for _, c := range CatmullRom(pts) {
+		p.CubicTo(c[1], c[2], c[3])
+	}
+}
@@ -0,0 +1,123 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// cubicAt returns the point t of the way along a cubic Bezier curve.
+func cubicAt(p0, c1, c2, p3 Point, t float64) Point {
+	u := 1 - t
+	return p0.Mul(u * u * u).Add(c1.Mul(3 * u * u * t)).Add(c2.Mul(3 * u * t * t)).Add(p3.Mul(t * t * t))
+}
+
+// farthest returns how far the curve strays from the polyline pts.
+func farthest(pts []Point, at func(t float64) Point) float64 {
+	var worst float64
+	for i := 0; i <= 1000; i++ {
+		p := at(float64(i) / 1000)
+		nearest := math.Inf(1)
+		for j := 1; j < len(pts); j++ {
+			a, b := pts[j-1], pts[j]
+			d := b.Sub(a)
+			t := 0.0
+			if l := d.X*d.X + d.Y*d.Y; l != 0 {
+				t = min(max(((p.X-a.X)*d.X+(p.Y-a.Y)*d.Y)/l, 0), 1)
+			}
+			nearest = min(nearest, p.Sub(a.Add(d.Mul(t))).Len())
+		}
+		worst = max(worst, nearest)
+	}
+	return worst
+}
+
+func Test_Flatten(t *testing.T) {
+	rand.Seed(24)
+	random := func() Point { return Pt(rand.Float64()*100, rand.Float64()*100) }
+	for i := 0; i < 50; i++ {
+		p0, c1, c2, p3 := random(), random(), random(), random()
+		for _, tolerance := range []float64{1, 0.1} {
+			pts := FlattenCubic([]Point{p0}, p0, c1, c2, p3, tolerance)
+			if pts[len(pts)-1] != p3 {
+				t.Fatal("flattened cubic does not end at its end")
+			}
+			if d := farthest(pts, func(t float64) Point { return cubicAt(p0, c1, c2, p3, t) }); d > tolerance {
+				t.Fatalf("cubic strays %v, tolerance %v", d, tolerance)
+			}
+
+			// a quad is a cubic with its control points 2/3 of the way to c1
+			qc1, qc2 := p0.Add(c1.Sub(p0).Mul(2.0/3)), p3.Add(c1.Sub(p3).Mul(2.0/3))
+			pts = FlattenQuad([]Point{p0}, p0, c1, p3, tolerance)
+			if d := farthest(pts, func(t float64) Point { return cubicAt(p0, qc1, qc2, p3, t) }); d > tolerance {
+				t.Fatalf("quad strays %v, tolerance %v", d, tolerance)
+			}
+		}
+	}
+
+	// a straight curve is one line
+	if pts := FlattenCubic(nil, Pt(0, 0), Pt(10, 10), Pt(20, 20), Pt(30, 30), 0.1); len(pts) != 1 {
+		t.Errorf("straight cubic took %d lines", len(pts))
+	}
+}
+
+func Test_CatmullRom(t *testing.T) {
+	pts := []Point{{0, 10}, {10, 0}, {20, 15}, {30, 5}}
+	curves := CatmullRom(pts)
+	if len(curves) != 3 {
+		t.Fatalf("got %d curves", len(curves))
+	}
+	for i, c := range curves {
+		if c[0] != pts[i] || c[3] != pts[i+1] {
+			t.Errorf("curve %d runs %v to %v", i, c[0], c[3])
+		}
+		// the curves meet smoothly: the control points either side of a
+		// joint are mirror images
+		if i > 0 {
+			if d := c[1].Sub(c[0]).Add(curves[i-1][2].Sub(c[0])); d.Len() > 1e-9 {
+				t.Errorf("curves %d and %d meet at an angle", i-1, i)
+			}
+		}
+	}
+	if CatmullRom(pts[:1]) != nil {
+		t.Error("a single point made curves")
+	}
+
+	var p Path
+	p.CatmullRom(pts)
+	flat := flatten(&p)[0]
+	if flat[0] != pts[0] || flat[len(flat)-1] != pts[3] {
+		t.Error("path spline does not run end to end")
+	}
+}
+
+func Test_DrawCurves(t *testing.T) {
+	// a straight curve draws the same as a line
+	line := draw(32, 32, func(dst gfx.Drawer) { Line(dst, image.Pt(2, 3), image.Pt(29, 17), color.White) })
+	cubic := draw(32, 32, func(dst gfx.Drawer) {
+		Cubic(dst, Pt(2, 3), Pt(2, 3), Pt(29, 17), Pt(29, 17), color.White)
+	})
+	if !same(line, cubic) {
+		t.Error("straight cubic differs from a line")
+	}
+
+	// a curve is connected: every pixel but the ends touches two others
+	quad := draw(32, 32, func(dst gfx.Drawer) { Quad(dst, Pt(2, 28), Pt(16, -20), Pt(29, 28), color.White) })
+	for p := range quad {
+		var n int
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if (dx != 0 || dy != 0) && quad[p.Add(image.Pt(dx, dy))] {
+					n++
+				}
+			}
+		}
+		if n == 0 || n == 1 && p != image.Pt(2, 28) && p != image.Pt(29, 28) {
+			t.Fatalf("%v has %d neighbours", p, n)
+		}
+	}
+}
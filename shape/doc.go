@@ -0,0 +1,38 @@
+package shape // github.com/sparques/gfx/shape
+/*
+Package shape draws lines and other geometric primitives onto any gfx.Drawer:
+lines and spans, outlined and filled rectangles (optionally with rounded
+corners), circles, ellipses, arcs, pie slices, polylines and polygons.
+
+Everything drawn is clipped to the destination's Bounds. Shapes are broken
+down into horizontal or vertical runs of pixels, each drawn with gfx.Fill, so
+destinations that are a gfx.Filler fill them natively; single pixels are drawn
+with Set.
+
+Integer coordinates name pixels. Outlines are one pixel wide, and a filled
+rectangle, ellipse or pie covers exactly its outline and the pixels inside it.
+Filled polygons instead follow the usual top-left rule, so that polygons
+sharing an edge neither overlap nor leave gaps.
+
+LineAA, CircleAA and EllipseAA draw anti-aliased outlines at sub-pixel
+positions given as Points. They shade pixels by coverage and blend them over
+what is already drawn with gfx.Blend, so they work on any Drawer.
+
+A Stroke draws polylines and polygons of any width, with a choice of caps and
+joins and an optional dash pattern.
+
+A Path is built of lines, quadratic and cubic Bezier curves and elliptical
+arcs. It can be stroked, or filled by the nonzero or even-odd rule with a
+scanline rasterizer, optionally anti-aliased. The rasterizer works a row at a
+time, so its memory use depends on the path and the destination's width, not
+its area.
+
+Curves are flattened into lines by splitting them until each piece is within
+a tolerance of straight; FlattenQuad and FlattenCubic do so directly, and Quad
+and Cubic draw the result. CatmullRom turns a series of points, such as the
+samples of a chart, into a smooth run of cubic curves through them.
+
+FloodFill and BoundaryFill fill the area around a seed pixel, bounded by a
+change of color or by a border color. They find it a run of pixels at a time
+with an explicit stack rather than recursion.
+*/
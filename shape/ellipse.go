@@ -0,0 +1,188 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+	"sort"
+
+	"github.com/sparques/gfx"
+)
+
+// Circle draws the one pixel wide outline of a circle.
+func Circle(dst gfx.Drawer, center image.Point, radius int, c color.Color) {
+	Ellipse(dst, center, radius, radius, c)
+}
+
+// FillCircle fills a circle; it covers exactly the pixels Circle draws and
+// those inside them.
+func FillCircle(dst gfx.Drawer, center image.Point, radius int, c color.Color) {
+	FillEllipse(dst, center, radius, radius, c)
+}
+
+// Ellipse draws the one pixel wide outline of an axis-aligned ellipse with
+// horizontal radius rx and vertical radius ry, using the midpoint algorithm.
+func Ellipse(dst gfx.Drawer, center image.Point, rx, ry int, c color.Color) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	roundedOutline(dst, center.X, center.Y, center.X, center.Y, rx, ry, c)
+}
+
+// FillEllipse fills an ellipse; it covers exactly the pixels Ellipse draws and
+// those inside them.
+func FillEllipse(dst gfx.Drawer, center image.Point, rx, ry int, c color.Color) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	roundedFill(dst, center.X, center.Y, center.X, center.Y, rx, ry, c)
+}
+
+// quadrant is one quadrant of the outline the midpoint ellipse algorithm draws,
+// with horizontal radius rx and vertical radius ry. Rather than walking the
+// whole outline, it works out each row on its own, so a huge ellipse mostly off
+// the destination costs only the rows that are drawn.
+//
+// The walk starts at 0, ry in region 1, where x steps every time and y steps
+// when the midpoint below is outside the ellipse. Once the slope passes -1, at
+// x1, y1, it carries on in region 2, where y steps every time and x steps when
+// the midpoint beside is inside.
+type quadrant struct {
+	rx, ry int
+	x1, y1 int
+}
+
+func newQuadrant(rx, ry int) quadrant {
+	q := quadrant{rx: rx, ry: ry}
+	if rx == 0 || ry == 0 {
+		return q
+	}
+	// y steps by at most one a column, so it can lag where the outline is steep
+	at := func(x int) int { return max(q.rowAt(x), q.rowAt(x-1)-1) }
+	q.x1 = 1 + sort.Search(rx, func(i int) bool {
+		return !mulLess(ry*ry, i+1, rx*rx, at(i+1))
+	})
+	q.y1 = at(q.x1)
+	return q
+}
+
+// row returns the run of x, from lo to hi inclusive, that the outline covers in
+// row y, for y from 0 to ry.
+func (q quadrant) row(y int) (lo, hi int) {
+	switch {
+	case q.rx == 0 || q.ry == 0:
+		if y == 0 {
+			return 0, q.rx
+		}
+		return 0, 0
+	case y >= q.y1:
+		if y < q.ry {
+			lo = q.lastIn(y+1) + 1
+		}
+		if y == q.y1 {
+			return min(lo, q.x1), q.x1
+		}
+		return lo, q.lastIn(y)
+	}
+	// x steps by at most one a row, so it can lag after region 1 too
+	x := max(q.x1, min(q.colAt(y), q.x1+q.y1-y))
+	return x, x
+}
+
+// rowAt returns the row region 1 is in at column x, were it to follow the
+// outline exactly: the least y whose midpoint below, x, y+½, is not inside.
+func (q quadrant) rowAt(x int) int {
+	return sort.Search(q.ry, func(y int) bool {
+		return sumSqCmp(2*q.ry*x, q.rx*(2*y+1), 2*q.rx*q.ry) >= 0
+	})
+}
+
+// lastIn returns the last column region 1 draws in row y: the greatest x whose
+// midpoint x, y-½ is inside. It returns -1 if there is none.
+func (q quadrant) lastIn(y int) int {
+	return sort.Search(q.rx+1, func(x int) bool {
+		return sumSqCmp(2*q.ry*x, q.rx*abs(2*y-1), 2*q.rx*q.ry) >= 0
+	}) - 1
+}
+
+// colAt returns the column region 2 is in at row y, were it to follow the
+// outline exactly: the greatest x whose midpoint x-½, y is inside or on it.
+func (q quadrant) colAt(y int) int {
+	return sort.Search(q.rx+1, func(x int) bool {
+		return sumSqCmp(q.ry*(2*x+1), 2*q.rx*y, 2*q.rx*q.ry) > 0
+	})
+}
+
+// sumSqCmp compares a²+b² with c², for a, b and c at least 0, without
+// overflowing. It returns -1, 0 or +1 as the sum is less, equal or greater.
+func sumSqCmp(a, b, c int) int {
+	ah, al := bits.Mul64(uint64(a), uint64(a))
+	bh, bl := bits.Mul64(uint64(b), uint64(b))
+	ch, cl := bits.Mul64(uint64(c), uint64(c))
+	sl, carry := bits.Add64(al, bl, 0)
+	sh, _ := bits.Add64(ah, bh, carry)
+	switch {
+	case sh < ch || sh == ch && sl < cl:
+		return -1
+	case sh == ch && sl == cl:
+		return 0
+	}
+	return 1
+}
+
+// mulLess reports whether a*b < c*d, for a, b, c and d at least 0, without
+// overflowing.
+func mulLess(a, b, c, d int) bool {
+	h0, l0 := bits.Mul64(uint64(a), uint64(b))
+	h1, l1 := bits.Mul64(uint64(c), uint64(d))
+	return h0 < h1 || h0 == h1 && l0 < l1
+}
+
+// roundedOutline draws the outline of a rectangle whose corners are quarter
+// ellipses centered at x0, y0 (top left) and x1, y1 (bottom right). An ellipse
+// is one whose corners share a center.
+func roundedOutline(dst gfx.Drawer, x0, y0, x1, y1, rx, ry int, c color.Color) {
+	q := newQuadrant(rx, ry)
+	draw := func(y, row int) {
+		lo, hi := q.row(y)
+		if lo == 0 {
+			HLine(dst, x0-hi, x1+hi, row, c)
+			return
+		}
+		HLine(dst, x0-hi, x0-lo, row, c)
+		HLine(dst, x1+lo, x1+hi, row, c)
+	}
+	cornerRows(dst.Bounds(), y0, y1, ry, draw)
+	if y1-y0 > 1 {
+		VLine(dst, x0-rx, y0+1, y1-1, c)
+		VLine(dst, x1+rx, y0+1, y1-1, c)
+	}
+}
+
+// roundedFill fills the shape roundedOutline draws.
+func roundedFill(dst gfx.Drawer, x0, y0, x1, y1, rx, ry int, c color.Color) {
+	q := newQuadrant(rx, ry)
+	cornerRows(dst.Bounds(), y0, y1, ry, func(y, row int) {
+		_, hi := q.row(y)
+		HLine(dst, x0-hi, x1+hi, row, c)
+	})
+	if y1-y0 > 1 {
+		span(dst, image.Rect(x0-rx, y0+1, x1+rx+1, y1), c)
+	}
+}
+
+// cornerRows calls draw with each y from 0 to ry for which the top corners'
+// row y0-y or the bottom corners' row y1+y lies within b, along with that row.
+// A row the two share is only drawn once.
+func cornerRows(b image.Rectangle, y0, y1, ry int, draw func(y, row int)) {
+	first, last := clipSteps(y0, -1, b.Min.Y, b.Max.Y)
+	for y := max(first, 0); y <= min(last, ry); y++ {
+		draw(y, y0-y)
+	}
+	first, last = clipSteps(y1, 1, b.Min.Y, b.Max.Y)
+	for y := max(first, 0); y <= min(last, ry); y++ {
+		if y1+y != y0-y {
+			draw(y, y1+y)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// inked returns the pixels of dst that are not transparent.
+func inked(dst *image.RGBA) map[image.Point]bool {
+	m := make(map[image.Point]bool)
+	b := dst.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if dst.RGBAAt(x, y).A != 0 {
+				m[image.Pt(x, y)] = true
+			}
+		}
+	}
+	return m
+}
+
+func same(a, b map[image.Point]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p := range a {
+		if !b[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// draw draws with f on a new w by h image and returns the inked pixels.
+func draw(w, h int, f func(dst gfx.Drawer)) map[image.Point]bool {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	f(dst)
+	return inked(dst)
+}
+
+func Test_Circle(t *testing.T) {
+	// the octant of a radius 5 circle, from the top going clockwise
+	circle := draw(16, 16, func(dst gfx.Drawer) { Circle(dst, image.Pt(8, 8), 5, color.White) })
+	for _, p := range []image.Point{{0, -5}, {1, -5}, {2, -5}, {3, -4}, {4, -3}, {5, -2}, {5, -1}, {5, 0}} {
+		for _, q := range []image.Point{p, {-p.X, p.Y}, {p.X, -p.Y}, {-p.X, -p.Y}, {p.Y, p.X}} {
+			if !circle[q.Add(image.Pt(8, 8))] {
+				t.Errorf("circle missing %v", q)
+			}
+		}
+	}
+	if len(circle) != 28 {
+		t.Errorf("circle has %d pixels", len(circle))
+	}
+}
+
+// midpoint walks the midpoint ellipse algorithm over one quadrant of an ellipse
+// step by step, and returns for each y from 0 to ry the run of x from lo[y] to
+// hi[y] inclusive that the outline covers in that row.
+func midpoint(rx, ry int) (lo, hi []int) {
+	lo, hi = make([]int, ry+1), make([]int, ry+1)
+	if rx == 0 || ry == 0 {
+		hi[0] = rx
+		return lo, hi
+	}
+
+	for i := range lo {
+		lo[i] = rx
+	}
+	plot := func(x, y int) {
+		lo[y], hi[y] = min(lo[y], x), max(hi[y], x)
+	}
+
+	// decision variables are scaled by 4 to stay in integers
+	rx2, ry2 := rx*rx, ry*ry
+	x, y := 0, ry
+	dx, dy := 0, 2*rx2*y
+	p := 4*ry2 - 4*rx2*ry + rx2
+	// region 1: the slope is shallower than -1, so x steps every time
+	for dx < dy {
+		plot(x, y)
+		x++
+		dx += 2 * ry2
+		if p < 0 {
+			p += 4 * (ry2 + dx)
+		} else {
+			y--
+			dy -= 2 * rx2
+			p += 4 * (ry2 + dx - dy)
+		}
+	}
+	// region 2: y steps every time
+	p = ry2*(2*x+1)*(2*x+1) + 4*rx2*(y-1)*(y-1) - 4*rx2*ry2
+	for y >= 0 {
+		plot(x, y)
+		y--
+		dy -= 2 * rx2
+		if p > 0 {
+			p += 4 * (rx2 - dy)
+		} else {
+			x++
+			dx += 2 * ry2
+			p += 4 * (rx2 - dy + dx)
+		}
+	}
+	return lo, hi
+}
+
+func Test_Quadrant(t *testing.T) {
+	rand.Seed(21)
+	check := func(rx, ry int) {
+		t.Helper()
+		lo, hi := midpoint(rx, ry)
+		q := newQuadrant(rx, ry)
+		for y := 0; y <= ry; y++ {
+			if l, h := q.row(y); l != lo[y] || h != hi[y] {
+				t.Fatalf("quadrant %d,%d row %d: got %d-%d, want %d-%d", rx, ry, y, l, h, lo[y], hi[y])
+			}
+		}
+	}
+	for rx := 0; rx <= 40; rx++ {
+		for ry := 0; ry <= 40; ry++ {
+			check(rx, ry)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		check(rand.Intn(2000), rand.Intn(2000))
+	}
+}
+
+func Test_HugeEllipse(t *testing.T) {
+	// only the rows on screen are worked out, so these are quick; the top of
+	// the circle is flat for thousands of pixels either side
+	const r = 2e7
+	circle := draw(20, 20, func(dst gfx.Drawer) { Circle(dst, image.Pt(10, r+5), r, color.White) })
+	disc := draw(20, 20, func(dst gfx.Drawer) { FillCircle(dst, image.Pt(10, r+5), r, color.White) })
+	if len(circle) != 20 || !circle[image.Pt(0, 5)] || !circle[image.Pt(19, 5)] {
+		t.Errorf("circle has %d pixels", len(circle))
+	}
+	if len(disc) != 20*15 || !disc[image.Pt(0, 5)] || disc[image.Pt(0, 4)] {
+		t.Errorf("disc has %d pixels", len(disc))
+	}
+	if got := draw(20, 20, func(dst gfx.Drawer) { Arc(dst, image.Pt(10, r+5), r, 0, math.Pi, color.White) }); !same(got, circle) {
+		t.Error("arc over the top differs from the circle")
+	}
+	if got := draw(20, 20, func(dst gfx.Drawer) { Pie(dst, image.Pt(10, r+5), r, 0, math.Pi, color.White) }); !same(got, disc) {
+		t.Error("pie over the top differs from the disc")
+	}
+}
+
+func Test_Ellipse(t *testing.T) {
+	rand.Seed(20)
+	for i := 0; i < 100; i++ {
+		center := image.Pt(rand.Intn(40)-4, rand.Intn(40)-4)
+		rx, ry := rand.Intn(20), rand.Intn(20)
+		outline := draw(32, 32, func(dst gfx.Drawer) { Ellipse(dst, center, rx, ry, color.White) })
+		filled := draw(32, 32, func(dst gfx.Drawer) { FillEllipse(dst, center, rx, ry, color.White) })
+
+		// clipped, each is a part of the same shape drawn without clipping
+		big := image.Rect(-24, -24, 72, 72)
+		whole := image.NewRGBA(big)
+		FillEllipse(whole, center, rx, ry, color.White)
+		if !same(filled, inked(whole.SubImage(image.Rect(0, 0, 32, 32)).(*image.RGBA))) {
+			t.Fatalf("ellipse %v %d,%d clipped wrong", center, rx, ry)
+		}
+
+		// the fill covers the outline, and spans it in every row
+		for p := range outline {
+			if !filled[p] {
+				t.Fatalf("ellipse %v %d,%d: %v outlined but not filled", center, rx, ry, p)
+			}
+		}
+		for p := range filled {
+			// the pixel's nearest corner is inside the ellipse, give or take
+			dx := max(math.Abs(float64(p.X-center.X))-0.5, 0) / float64(rx)
+			dy := max(math.Abs(float64(p.Y-center.Y))-0.5, 0) / float64(ry)
+			if rx > 0 && ry > 0 && dx*dx+dy*dy > 1.1 {
+				t.Fatalf("ellipse %v %d,%d: %v is far outside", center, rx, ry, p)
+			}
+		}
+	}
+
+	if got := draw(8, 8, func(dst gfx.Drawer) { Ellipse(dst, image.Pt(4, 4), 3, 0, color.White) }); len(got) != 7 {
+		t.Errorf("flat ellipse has %d pixels", len(got))
+	}
+}
+
+func Test_Rects(t *testing.T) {
+	r := image.Rect(2, 3, 12, 9)
+	rect := draw(16, 16, func(dst gfx.Drawer) { Rect(dst, r, color.White) })
+	if len(rect) != 2*10+2*6-4 || !rect[image.Pt(2, 3)] || !rect[image.Pt(11, 8)] || rect[image.Pt(12, 9)] {
+		t.Errorf("rect has %d pixels", len(rect))
+	}
+	if got := draw(16, 16, func(dst gfx.Drawer) { RoundRect(dst, r, 0, color.White) }); !same(got, rect) {
+		t.Error("square-cornered RoundRect differs from Rect")
+	}
+
+	filled := draw(16, 16, func(dst gfx.Drawer) { FillRect(dst, r, color.White) })
+	if got := draw(16, 16, func(dst gfx.Drawer) { FillRoundRect(dst, r, 0, color.White) }); !same(got, filled) || len(filled) != 60 {
+		t.Error("square-cornered FillRoundRect differs from FillRect")
+	}
+
+	// as round as it gets, a square rounded rect is a circle
+	circle := draw(16, 16, func(dst gfx.Drawer) { Circle(dst, image.Pt(7, 7), 5, color.White) })
+	if got := draw(16, 16, func(dst gfx.Drawer) { RoundRect(dst, image.Rect(2, 2, 13, 13), 99, color.White) }); !same(got, circle) {
+		t.Error("fully rounded square differs from a circle")
+	}
+
+	round := draw(16, 16, func(dst gfx.Drawer) { RoundRect(dst, r, 2, color.White) })
+	roundFilled := draw(16, 16, func(dst gfx.Drawer) { FillRoundRect(dst, r, 2, color.White) })
+	if round[image.Pt(2, 3)] || roundFilled[image.Pt(2, 3)] || !round[image.Pt(2, 5)] || !round[image.Pt(4, 3)] {
+		t.Error("corners not rounded")
+	}
+	for p := range round {
+		if !roundFilled[p] {
+			t.Fatalf("%v outlined but not filled", p)
+		}
+	}
+
+	if got := draw(16, 16, func(dst gfx.Drawer) { HLine(dst, 9, 3, 2, color.White); VLine(dst, 1, 14, -5, color.White) }); len(got) != 7+15 {
+		t.Errorf("lines drew %d pixels", len(got))
+	}
+}
+
+func Test_Arcs(t *testing.T) {
+	center := image.Pt(10, 10)
+	circle := draw(24, 24, func(dst gfx.Drawer) { Circle(dst, center, 8, color.White) })
+	if got := draw(24, 24, func(dst gfx.Drawer) { Arc(dst, center, 8, 1, 1+2*math.Pi, color.White) }); !same(got, circle) {
+		t.Error("full arc differs from a circle")
+	}
+	disc := draw(24, 24, func(dst gfx.Drawer) { FillCircle(dst, center, 8, color.White) })
+	if got := draw(24, 24, func(dst gfx.Drawer) { Pie(dst, center, 8, 0, 7, color.White) }); !same(got, disc) {
+		t.Error("full pie differs from a disc")
+	}
+
+	// a quarter from 0 to 90 degrees is the top right, as seen on screen
+	arc := draw(24, 24, func(dst gfx.Drawer) { Arc(dst, center, 8, 0, math.Pi/2, color.White) })
+	pie := draw(24, 24, func(dst gfx.Drawer) { Pie(dst, center, 8, 0, math.Pi/2, color.White) })
+	if !arc[image.Pt(18, 10)] || !arc[image.Pt(10, 2)] || arc[image.Pt(2, 10)] || len(arc) != len(circle)/4+1 {
+		t.Errorf("quarter arc has %d pixels", len(arc))
+	}
+	for p := range disc {
+		if want := p.X >= 10 && p.Y <= 10; pie[p] != want {
+			t.Fatalf("quarter pie at %v is %v", p, pie[p])
+		}
+	}
+
+	// a sweep past 2π wraps: from 3/4 to 1/4 of a turn is the right half
+	half := draw(24, 24, func(dst gfx.Drawer) { Pie(dst, center, 8, 3*math.Pi/2, math.Pi/2, color.White) })
+	for p := range disc {
+		if want := p.X >= 10; half[p] != want {
+			t.Fatalf("half pie at %v is %v", p, half[p])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/sparques/gfx"
+)
+
+// FloodFill fills the area of dst around seed with c: seed and every pixel
+// reachable from it through its four neighbours whose color is within
+// tolerance of seed's. Colors are within tolerance if none of their 8 bit
+// channels differ by more than it.
+//
+// Each run of pixels in a row is found and filled with one span, keeping a
+// stack of runs still to look above or below; the stack is on the heap and
+// grows with the shape's complexity, not its area. If c is itself within
+// tolerance of seed's color, filled pixels are told apart from unfilled ones
+// with a bit per pixel of dst.
+//
+// *gfx.RGBA and *image.RGBA have their pixels read directly; anything else is
+// read with At.
+func FloodFill(dst gfx.Drawer, seed image.Point, c color.Color, tolerance uint8) {
+	b := dst.Bounds()
+	if !seed.In(b) {
+		return
+	}
+	read := pixelReader(dst)
+	target := read(seed.X, seed.Y)
+	match := func(p [4]uint8) bool {
+		for i := range p {
+			if abs(int(p[i])-int(target[i])) > int(tolerance) {
+				return false
+			}
+		}
+		return true
+	}
+
+	inside := func(x, y int) bool {
+		return image.Pt(x, y).In(b) && match(read(x, y))
+	}
+	fill := func(x0, x1, y int) {
+		span(dst, image.Rect(x0, y, x1+1, y+1), c)
+	}
+
+	if fc := convert(dst, c); match(fc) {
+		if fc == target {
+			// nothing would change
+			return
+		}
+		// filled pixels would still count as inside; remember them instead
+		filled := make([]uint64, (b.Dx()*b.Dy()+63)/64)
+		bit := func(x, y int) int {
+			return (y-b.Min.Y)*b.Dx() + x - b.Min.X
+		}
+		matched := inside
+		inside = func(x, y int) bool {
+			if !matched(x, y) {
+				return false
+			}
+			i := bit(x, y)
+			return filled[i/64]&(1<<(i%64)) == 0
+		}
+		fill = func(x0, x1, y int) {
+			span(dst, image.Rect(x0, y, x1+1, y+1), c)
+			for x := x0; x <= x1; x++ {
+				i := bit(x, y)
+				filled[i/64] |= 1 << (i % 64)
+			}
+		}
+	}
+
+	spanFill(seed, inside, fill)
+}
+
+// BoundaryFill fills the area of dst around seed with c, up to pixels of the
+// border color: seed and every pixel reachable from it through its four
+// neighbours that is neither the border color nor already c. It works as
+// FloodFill does, but needs no more than its stack of runs.
+func BoundaryFill(dst gfx.Drawer, seed image.Point, c, border color.Color) {
+	b := dst.Bounds()
+	read := pixelReader(dst)
+	bc, fc := convert(dst, border), convert(dst, c)
+	inside := func(x, y int) bool {
+		if !image.Pt(x, y).In(b) {
+			return false
+		}
+		p := read(x, y)
+		return p != bc && p != fc
+	}
+	spanFill(seed, inside, func(x0, x1, y int) {
+		span(dst, image.Rect(x0, y, x1+1, y+1), c)
+	})
+}
+
+// fillRun is a run of pixels from x0 to x1 inclusive at y, whose neighbours in
+// row y+dy are still to be looked at.
+type fillRun struct {
+	x0, x1, y, dy int
+}
+
+// spanFill fills the pixels reachable from seed for which inside is true,
+// calling fill with each run of them in a row, from x0 to x1 inclusive. Once
+// filled, pixels must no longer be inside.
+func spanFill(seed image.Point, inside func(x, y int) bool, fill func(x0, x1, y int)) {
+	if !inside(seed.X, seed.Y) {
+		return
+	}
+	stack := []fillRun{{seed.X, seed.X, seed.Y, 1}, {seed.X, seed.X, seed.Y - 1, -1}}
+	for len(stack) > 0 {
+		r := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		// find each run in row r.y touching the run above or below it
+		for x := r.x0; x <= r.x1; x++ {
+			if !inside(x, r.y) {
+				continue
+			}
+			start, end := x, x
+			for inside(start-1, r.y) {
+				start--
+			}
+			for inside(end+1, r.y) {
+				end++
+			}
+			fill(start, end, r.y)
+
+			// carry on away from where we came, and look back where the run
+			// reaches past the one it came from
+			stack = append(stack, fillRun{start, end, r.y + r.dy, r.dy})
+			if start < r.x0 {
+				stack = append(stack, fillRun{start, r.x0 - 1, r.y - r.dy, -r.dy})
+			}
+			if end > r.x1 {
+				stack = append(stack, fillRun{r.x1 + 1, end, r.y - r.dy, -r.dy})
+			}
+			x = end + 1
+		}
+	}
+}
+
+// pixelReader returns a function reading the 8 bit, premultiplied channels of
+// dst's pixels.
+func pixelReader(dst gfx.Drawer) func(x, y int) [4]uint8 {
+	var rgba *image.RGBA
+	switch d := dst.(type) {
+	case *gfx.RGBA:
+		rgba = d.RGBA
+	case *image.RGBA:
+		rgba = d
+	default:
+		return func(x, y int) [4]uint8 {
+			return rgba8(dst.At(x, y))
+		}
+	}
+	return func(x, y int) [4]uint8 {
+		i := rgba.PixOffset(x, y)
+		return [4]uint8(rgba.Pix[i : i+4])
+	}
+}
+
+// convert returns c as dst would store it, in 8 bit, premultiplied channels. A
+// dst without a color model, such as a gfx.SoftScreenOf without Convert, is
+// taken to store c as it is.
+func convert(dst gfx.Drawer, c color.Color) [4]uint8 {
+	if m := dst.ColorModel(); m != nil {
+		c = m.Convert(c)
+	}
+	return rgba8(c)
+}
+
+func rgba8(c color.Color) [4]uint8 {
+	r, g, b, a := c.RGBA()
+	return [4]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)}
+}
@@ -0,0 +1,196 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// generic hides the type of an image, leaving only the Drawer methods.
+type generic struct {
+	gfx.Drawer
+}
+
+// floodRef is the textbook flood fill, a pixel at a time from a queue.
+func floodRef(dst *image.RGBA, seed image.Point, c color.RGBA) {
+	target := dst.RGBAAt(seed.X, seed.Y)
+	if target == c {
+		return
+	}
+	queue := []image.Point{seed}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if !p.In(dst.Bounds()) || dst.RGBAAt(p.X, p.Y) != target {
+			continue
+		}
+		dst.SetRGBA(p.X, p.Y, c)
+		queue = append(queue, p.Add(image.Pt(1, 0)), p.Add(image.Pt(-1, 0)), p.Add(image.Pt(0, 1)), p.Add(image.Pt(0, -1)))
+	}
+}
+
+func Test_FloodFill(t *testing.T) {
+	rand.Seed(25)
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	for i := 0; i < 50; i++ {
+		// random noise makes mazes of every shape
+		noise := image.NewRGBA(image.Rect(-3, 2, 37, 30))
+		for j := range noise.Pix {
+			if j%4 == 3 || rand.Intn(5) < 2 {
+				noise.Pix[j] = 0xFF
+			}
+		}
+		// keep it to black and white
+		for j := 0; j < len(noise.Pix); j += 4 {
+			v := noise.Pix[j]
+			noise.Pix[j+1], noise.Pix[j+2] = v, v
+		}
+		b := noise.Bounds()
+		seed := image.Pt(b.Min.X+rand.Intn(b.Dx()), b.Min.Y+rand.Intn(b.Dy()))
+
+		want := image.NewRGBA(b)
+		copy(want.Pix, noise.Pix)
+		floodRef(want, seed, red)
+
+		fast := image.NewRGBA(b)
+		copy(fast.Pix, noise.Pix)
+		FloodFill(fast, seed, red, 0)
+
+		wrapped := gfx.NewRGBA(image.NewRGBA(b))
+		copy(wrapped.Pix, noise.Pix)
+		FloodFill(wrapped, seed, red, 0)
+
+		slow := image.NewRGBA(b)
+		copy(slow.Pix, noise.Pix)
+		FloodFill(generic{slow}, seed, red, 0)
+
+		for name, got := range map[string]*image.RGBA{"image.RGBA": fast, "gfx.RGBA": wrapped.RGBA, "generic": slow} {
+			if string(got.Pix) != string(want.Pix) {
+				t.Fatalf("%d: %s filled from %v differently", i, name, seed)
+			}
+		}
+	}
+
+	// neighbours only across edges, not corners
+	got := draw(4, 4, func(dst gfx.Drawer) {
+		dst.Set(1, 0, color.White)
+		dst.Set(0, 1, color.White)
+		FloodFill(dst, image.Pt(0, 0), red, 0)
+	})
+	if len(got) != 3 {
+		t.Errorf("filled through a corner, %d pixels inked", len(got))
+	}
+
+	// seeds outside dst do nothing
+	if got := draw(4, 4, func(dst gfx.Drawer) { FloodFill(dst, image.Pt(4, 0), red, 0) }); len(got) != 0 {
+		t.Errorf("seed outside filled %d pixels", len(got))
+	}
+
+	// a screen without a color model stores colors as they are
+	screen := gfx.NewSoftScreenOf[gfx.RGB565BE](image.Rect(0, 0, 1, 1), image.Rect(0, 0, 6, 4), image.Rect(0, 0, 6, 4))
+	white, native := gfx.NewRGB565BE(0xFF, 0xFF, 0xFF), gfx.NewRGB565BE(0xFF, 0, 0)
+	VLine(screen, 3, 0, 3, white)
+	FloodFill(screen, image.Pt(0, 0), native, 0)
+	var n int
+	for _, p := range screen.Pix {
+		if p == native {
+			n++
+		}
+	}
+	if n != 12 || screen.At(3, 2) != white || screen.At(4, 2) != gfx.RGB565BE(0) {
+		t.Errorf("soft screen: filled %d pixels", n)
+	}
+}
+
+func Test_FloodFillTolerance(t *testing.T) {
+	// a ramp getting brighter by 10 a column
+	ramp := image.NewRGBA(image.Rect(0, 0, 20, 3))
+	for x := 0; x < 20; x++ {
+		for y := 0; y < 3; y++ {
+			v := uint8(x * 10)
+			ramp.SetRGBA(x, y, color.RGBA{v, v, v, 0xFF})
+		}
+	}
+	red := color.RGBA{0xFF, 0, 0, 0xFF}
+	filled := func(dst *image.RGBA) (n int) {
+		for x := 0; x < 20; x++ {
+			if dst.RGBAAt(x, 1) == red {
+				n++
+			}
+		}
+		return
+	}
+
+	for _, tc := range []struct {
+		seed      int
+		tolerance uint8
+		want      int
+	}{
+		{0, 0, 1},
+		{0, 9, 1},
+		{0, 10, 2},
+		{0, 45, 5},
+		{10, 20, 5},
+		{19, 255, 20},
+	} {
+		dst := image.NewRGBA(ramp.Bounds())
+		copy(dst.Pix, ramp.Pix)
+		FloodFill(dst, image.Pt(tc.seed, 1), red, tc.tolerance)
+		if n := filled(dst); n != tc.want {
+			t.Errorf("from %d within %d: filled %d columns, want %d", tc.seed, tc.tolerance, n, tc.want)
+		}
+	}
+
+	// filling with a color that still matches must still stop
+	dst := image.NewRGBA(ramp.Bounds())
+	copy(dst.Pix, ramp.Pix)
+	grey := color.RGBA{15, 15, 15, 0xFF}
+	FloodFill(dst, image.Pt(0, 0), grey, 25)
+	for x := 0; x < 20; x++ {
+		if got := dst.RGBAAt(x, 2) == grey; got != (x < 3) {
+			t.Errorf("column %d filled: %v", x, got)
+		}
+	}
+
+	// filling with the seed's own color changes nothing
+	dst = image.NewRGBA(ramp.Bounds())
+	copy(dst.Pix, ramp.Pix)
+	FloodFill(dst, image.Pt(5, 1), ramp.RGBAAt(5, 1), 0)
+	if string(dst.Pix) != string(ramp.Pix) {
+		t.Error("refilling with the same color changed pixels")
+	}
+}
+
+func Test_BoundaryFill(t *testing.T) {
+	// native colors, so that a soft screen without a color model takes them
+	red := gfx.NewRGB565BE(0xFF, 0, 0)
+	blue := gfx.NewRGB565BE(0, 0, 0xFF)
+	white := gfx.NewRGB565BE(0xFF, 0xFF, 0xFF)
+	for _, dst := range []gfx.Drawer{
+		image.NewRGBA(image.Rect(0, 0, 24, 24)),
+		gfx.NewRGB565(image.Rect(0, 0, 24, 24)),
+		gfx.NewSoftScreenOf[gfx.RGB565BE](image.Rect(0, 0, 1, 1), image.Rect(0, 0, 24, 24), image.Rect(0, 0, 24, 24)),
+	} {
+		// a ring with scribbles inside it, which are filled over
+		Circle(dst, image.Pt(12, 12), 8, red)
+		Line(dst, image.Pt(8, 10), image.Pt(16, 14), white)
+		FillRect(dst, image.Rect(10, 14, 13, 17), blue)
+		BoundaryFill(dst, image.Pt(12, 12), blue, red)
+
+		for y := 0; y < 24; y++ {
+			for x := 0; x < 24; x++ {
+				d := image.Pt(x-12, y-12)
+				r, _, b, _ := dst.At(x, y).RGBA()
+				switch n := d.X*d.X + d.Y*d.Y; {
+				case n < 7*7 && (r != 0 || b != 0xFFFF):
+					t.Errorf("%T: %d,%d inside not filled", dst, x, y)
+				case n > 9*9 && b != 0:
+					t.Errorf("%T: %d,%d outside filled", dst, x, y)
+				}
+			}
+		}
+	}
+}
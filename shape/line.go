@@ -0,0 +1,215 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/sparques/gfx"
+)
+
+// Line draws a one pixel wide line from p0 to p1, including both ends, using
+// Bresenham's algorithm. Each horizontal or vertical run of the line is drawn
+// as one span, so horizontal and vertical lines are a single Fill. Only the
+// part of the line within dst's bounds is walked, however long it is.
+func Line(dst gfx.Drawer, p0, p1 image.Point, c color.Color) {
+	b := dst.Bounds()
+	box := image.Rect(min(p0.X, p1.X), min(p0.Y, p1.Y), max(p0.X, p1.X)+1, max(p0.Y, p1.Y)+1)
+	if !box.Overlaps(b) {
+		return
+	}
+
+	dx, dy := abs(p1.X-p0.X), abs(p1.Y-p0.Y)
+	sx, sy := sign(p1.X-p0.X), sign(p1.Y-p0.Y)
+
+	if dx >= dy {
+		// x major: a run is the pixels sharing a row
+		lineRuns(p0.X, p0.Y, dx, dy, sx, sy, b.Min.X, b.Max.X, b.Min.Y, b.Max.Y, func(x0, x1, y int) {
+			span(dst, runRect(x0, x1, y, true), c)
+		})
+		return
+	}
+
+	// y major: a run is the pixels sharing a column
+	lineRuns(p0.Y, p0.X, dy, dx, sy, sx, b.Min.Y, b.Max.Y, b.Min.X, b.Max.X, func(y0, y1, x int) {
+		span(dst, runRect(y0, y1, x, false), c)
+	})
+}
+
+// lineRuns is Bresenham's algorithm for a line starting at u0 along its major
+// axis and v0 along its minor one, taking du steps of su along the major axis,
+// dv of which also step sv along the minor. It calls run with each run of
+// pixels sharing a minor coordinate v, from a to b inclusive along the major
+// axis.
+//
+// Only steps landing within [uMin, uMax) and [vMin, vMax) are taken: the first
+// is worked out directly, error term and all, and the walk stops at the last.
+func lineRuns(u0, v0, du, dv, su, sv, uMin, uMax, vMin, vMax int, run func(a, b, v int)) {
+	h := du / 2
+	first, last := clipSteps(u0, su, uMin, uMax)
+	first, last = max(first, 0), min(last, du)
+	if dv > 0 {
+		// after step i, the line has moved ceil((i*dv - h) / du) along the minor
+		// axis; find the steps where that is in range
+		lo, hi := clipSteps(v0, sv, vMin, vMax)
+		first = max(first, floorDiv((lo-1)*du+h, dv)+1)
+		last = min(last, floorDiv(hi*du+h, dv))
+	}
+	if first > last {
+		return
+	}
+
+	var k int
+	if du > 0 {
+		k = ceilDiv(first*dv-h, du)
+	}
+	err := h - first*dv + k*du
+	u, v := u0+first*su, v0+k*sv
+	start := u
+	for i := first; ; i++ {
+		if i == last {
+			run(start, u, v)
+			return
+		}
+		err -= dv
+		if err < 0 {
+			run(start, u, v)
+			v += sv
+			err += du
+			start = u + su
+		}
+		u += su
+	}
+}
+
+// clipSteps returns the range of steps i, first to last inclusive, for which
+// from+i*step is in [lo, hi). A step of zero is in range only at i == 0.
+func clipSteps(from, step, lo, hi int) (first, last int) {
+	switch {
+	case step > 0:
+		return lo - from, hi - 1 - from
+	case step < 0:
+		return from - (hi - 1), from - lo
+	case from >= lo && from < hi:
+		return 0, 0
+	}
+	return 1, 0
+}
+
+// runRect returns the rectangle covering a run from a to b inclusive (in either
+// order) at other, which is a row if horizontal, otherwise a column.
+func runRect(a, b, other int, horizontal bool) image.Rectangle {
+	if a > b {
+		a, b = b, a
+	}
+	if horizontal {
+		return image.Rect(a, other, b+1, other+1)
+	}
+	return image.Rect(other, a, other+1, b+1)
+}
+
+// ThickLine draws a line width pixels wide from p0 to p1, with round ends so
+// that lines sharing an end join smoothly. A width of 1 or less is a Line.
+func ThickLine(dst gfx.Drawer, p0, p1 image.Point, width int, c color.Color) {
+	if width <= 1 {
+		Line(dst, p0, p1, c)
+		return
+	}
+
+	a, b := PointOf(p0), PointOf(p1)
+	r := float64(width) / 2
+
+	if d := b.Sub(a); d != (Point{}) {
+		// the body of the line is a rectangle r either side of it
+		n := Pt(-d.Y, d.X).Mul(r / d.Len())
+		fillConvex(dst, []Point{a.Add(n), b.Add(n), b.Sub(n), a.Sub(n)}, c)
+	}
+	disc(dst, a, r, c)
+	disc(dst, b, r, c)
+}
+
+// fillConvex fills the convex polygon pts, drawing each pixel whose center is
+// inside it or on its top or left edge.
+func fillConvex(dst gfx.Drawer, pts []Point, c color.Color) {
+	top, bottom := math.Inf(1), math.Inf(-1)
+	for _, p := range pts {
+		top, bottom = min(top, p.Y), max(bottom, p.Y)
+	}
+
+	b := dst.Bounds()
+	y0, y1 := max(centerFrom(top), b.Min.Y), min(centerTo(bottom), b.Max.Y-1)
+	for y := y0; y <= y1; y++ {
+		cy := float64(y)
+		left, right := math.Inf(1), math.Inf(-1)
+		for i, p := range pts {
+			q := pts[(i+1)%len(pts)]
+			if (p.Y <= cy) == (q.Y <= cy) {
+				continue
+			}
+			x := p.X + (cy-p.Y)*(q.X-p.X)/(q.Y-p.Y)
+			left, right = min(left, x), max(right, x)
+		}
+		if left > right {
+			continue
+		}
+		span(dst, image.Rect(centerFrom(left), y, centerFrom(right), y+1), c)
+	}
+}
+
+// disc fills the pixels whose centers are within r of center.
+func disc(dst gfx.Drawer, center Point, r float64, c color.Color) {
+	b := dst.Bounds()
+	y0, y1 := max(centerFrom(center.Y-r), b.Min.Y), min(centerTo(center.Y+r), b.Max.Y-1)
+	for y := y0; y <= y1; y++ {
+		dy := float64(y) - center.Y
+		half := math.Sqrt(max(r*r-dy*dy, 0))
+		span(dst, image.Rect(centerFrom(center.X-half), y, centerTo(center.X+half)+1, y+1), c)
+	}
+}
+
+// centerFrom returns the first pixel whose center is at or after v.
+func centerFrom(v float64) int {
+	return int(math.Ceil(v))
+}
+
+// centerTo returns the last pixel whose center is at or before v.
+func centerTo(v float64) int {
+	return int(math.Floor(v))
+}
+
+// span fills r, clipped to dst. A single pixel is Set rather than filled.
+func span(dst gfx.Drawer, r image.Rectangle, c color.Color) {
+	r = r.Intersect(dst.Bounds())
+	switch {
+	case r.Empty():
+	case r.Dx() == 1 && r.Dy() == 1:
+		dst.Set(r.Min.X, r.Min.Y, c)
+	default:
+		gfx.Fill(dst, r, c)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// floorDiv returns a/b rounded down, for b > 0.
+func floorDiv(a, b int) int {
+	if a >= 0 {
+		return a / b
+	}
+	return -((-a + b - 1) / b)
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// bresenham is the textbook algorithm, drawing pixel by pixel.
+func bresenham(dst gfx.Drawer, p0, p1 image.Point, c color.Color) {
+	dx, dy := abs(p1.X-p0.X), abs(p1.Y-p0.Y)
+	sx, sy := sign(p1.X-p0.X), sign(p1.Y-p0.Y)
+	if dx >= dy {
+		err := dx / 2
+		for x, y := p0.X, p0.Y; ; x += sx {
+			dst.Set(x, y, c)
+			if x == p1.X {
+				return
+			}
+			if err -= dy; err < 0 {
+				y += sy
+				err += dx
+			}
+		}
+	}
+	err := dy / 2
+	for x, y := p0.X, p0.Y; ; y += sy {
+		dst.Set(x, y, c)
+		if y == p1.Y {
+			return
+		}
+		if err -= dx; err < 0 {
+			x += sx
+			err += dy
+		}
+	}
+}
+
+func Test_Line(t *testing.T) {
+	rand.Seed(19)
+	bounds := image.Rect(0, 0, 32, 32)
+	for i := 0; i < 500; i++ {
+		p0 := image.Pt(rand.Intn(48)-8, rand.Intn(48)-8)
+		p1 := image.Pt(rand.Intn(48)-8, rand.Intn(48)-8)
+		got, want := gfx.NewRGB565(bounds), image.NewRGBA(bounds)
+		Line(got, p0, p1, color.White)
+		bresenham(want, p0, p1, color.White)
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				if (got.At(x, y) == got.ColorModel().Convert(color.White)) != (want.RGBAAt(x, y).A != 0) {
+					t.Fatalf("line %v-%v differs at (%d,%d)", p0, p1, x, y)
+				}
+			}
+		}
+	}
+}
+
+func Test_HugeLine(t *testing.T) {
+	// only the steps on screen are taken, so these are quick
+	tests := []struct {
+		p0, p1 image.Point
+		want   []image.Point
+	}{
+		// the step down comes halfway, far off to the left
+		{image.Pt(-2e9, 5), image.Pt(10, 6), []image.Point{{0, 6}, {10, 6}}},
+		{image.Pt(5, 2e9), image.Pt(6, -10), []image.Point{{6, 19}, {6, 0}}},
+		{image.Pt(-1e9, -1e9), image.Pt(1e9, 1e9), []image.Point{{0, 0}, {19, 19}}},
+		{image.Pt(-1e9, 1e9+19), image.Pt(1e9, -1e9+19), []image.Point{{0, 19}, {19, 0}}},
+	}
+	for _, test := range tests {
+		got := draw(20, 20, func(dst gfx.Drawer) { Line(dst, test.p0, test.p1, color.White) })
+		a, b := test.want[0], test.want[1]
+		want := draw(20, 20, func(dst gfx.Drawer) { bresenham(dst, a, b, color.White) })
+		if !same(got, want) {
+			t.Errorf("line %v-%v drew %d pixels", test.p0, test.p1, len(got))
+		}
+	}
+}
+
+func Test_ThickLine(t *testing.T) {
+	dst := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	ThickLine(dst, image.Pt(4, 4), image.Pt(24, 24), 5, color.White)
+	inked := func(x, y int) bool { return dst.RGBAAt(x, y).A != 0 }
+
+	// the line is symmetric about its diagonal and has round ends
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if inked(x, y) != inked(y, x) || inked(x, y) != inked(28-x, 28-y) {
+				t.Fatalf("asymmetric at (%d,%d)", x, y)
+			}
+		}
+	}
+	if !inked(14, 14) || !inked(13, 15) || inked(12, 16) || !inked(4, 2) || inked(4, 1) || inked(2, 2) {
+		t.Error("thick line drawn wrong")
+	}
+
+	// a thick dot is a disc
+	dst = image.NewRGBA(image.Rect(0, 0, 8, 8))
+	ThickLine(dst, image.Pt(3, 3), image.Pt(3, 3), 4, color.White)
+	if !inked(1, 3) || inked(0, 3) || !inked(3, 5) || inked(3, 6) || inked(1, 1) {
+		t.Error("thick dot drawn wrong")
+	}
+}
@@ -0,0 +1,258 @@
+package shape
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/sparques/gfx"
+)
+
+// DefaultTolerance is the Tolerance used when a Path's is zero.
+const DefaultTolerance = 0.25
+
+// maxFlattenDepth bounds how many times a curve is split in half while being
+// flattened, and so the stack it takes, to at most 2^maxFlattenDepth lines.
+const maxFlattenDepth = 10
+
+// Path is a shape made of subpaths of lines and curves. Each subpath starts
+// with MoveTo and ends at the next MoveTo, or with Close. Curves are kept as
+// given and flattened into lines when the path is drawn. A curve added with no
+// current point starts from the origin.
+type Path struct {
+	// Tolerance is how far the lines curves are flattened into may stray from
+	// the true curve, in pixels. Zero means DefaultTolerance.
+	Tolerance float64
+
+	ops            []pathOp
+	start, current Point
+	begun          bool
+}
+
+type opKind int
+
+const (
+	opMove opKind = iota
+	opLine
+	opQuad
+	opCubic
+	opClose
+)
+
+type pathOp struct {
+	kind opKind
+	p    [3]Point
+}
+
+// Reset empties the path, keeping its memory for reuse.
+func (p *Path) Reset() {
+	p.ops = p.ops[:0]
+	p.start, p.current, p.begun = Point{}, Point{}, false
+}
+
+// MoveTo starts a new subpath at pt.
+func (p *Path) MoveTo(pt Point) {
+	p.ops = append(p.ops, pathOp{kind: opMove, p: [3]Point{pt}})
+	p.start, p.current, p.begun = pt, pt, true
+}
+
+// begin starts a subpath at pt if there is no current point.
+func (p *Path) begin(pt Point) bool {
+	if !p.begun {
+		p.MoveTo(pt)
+		return false
+	}
+	return true
+}
+
+// LineTo adds a line from the current point to pt. Without a current point, it
+// is a MoveTo.
+func (p *Path) LineTo(pt Point) {
+	if p.begin(pt) {
+		p.ops = append(p.ops, pathOp{kind: opLine, p: [3]Point{pt}})
+		p.current = pt
+	}
+}
+
+// QuadTo adds a quadratic Bezier curve from the current point to pt, with
+// control point c.
+func (p *Path) QuadTo(c, pt Point) {
+	p.begin(p.current)
+	p.ops = append(p.ops, pathOp{kind: opQuad, p: [3]Point{c, pt}})
+	p.current = pt
+}
+
+// CubicTo adds a cubic Bezier curve from the current point to pt, with
+// control points c1 and c2.
+func (p *Path) CubicTo(c1, c2, pt Point) {
+	p.begin(p.current)
+	p.ops = append(p.ops, pathOp{kind: opCubic, p: [3]Point{c1, c2, pt}})
+	p.current = pt
+}
+
+// ArcTo adds an elliptical arc from the current point to pt, as in SVG: the
+// ellipse has radii rx and ry and is rotated by rotation radians, and of the
+// four arcs that fit, large picks one sweeping more than 180 degrees and
+// sweep one running clockwise on screen. Radii too small to reach pt are
+// scaled up until they do, and a zero radius gives a line.
+func (p *Path) ArcTo(rx, ry, rotation float64, large, sweep bool, pt Point) {
+	from := p.current
+	if !p.begin(pt) || from == pt {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		p.LineTo(pt)
+		return
+	}
+
+	// find the center, per the SVG implementation notes (F.6.5)
+	sin, cos := math.Sincos(rotation)
+	half := from.Sub(pt).Mul(0.5)
+	x1, y1 := cos*half.X+sin*half.Y, -sin*half.X+cos*half.Y
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	co := math.Sqrt(max(num/(rx*rx*y1*y1+ry*ry*x1*x1), 0))
+	if large == sweep {
+		co = -co
+	}
+	cx, cy := co*rx*y1/ry, -co*ry*x1/rx
+	center := Pt(cos*cx-sin*cy, sin*cx+cos*cy).Add(from.Add(pt).Mul(0.5))
+
+	angle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := angle(1, 0, (x1-cx)/rx, (y1-cy)/ry)
+	delta := angle((x1-cx)/rx, (y1-cy)/ry, (-x1-cx)/rx, (-y1-cy)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	// approximate each quarter turn or less with a cubic
+	n := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	// on maps a point of the unit circle onto the ellipse
+	on := func(x, y float64) Point {
+		x, y = x*rx, y*ry
+		return Pt(cos*x-sin*y, sin*x+cos*y).Add(center)
+	}
+	for i := 0; i < n; i++ {
+		a, b := theta+float64(i)*step, theta+float64(i+1)*step
+		sa, ca := math.Sincos(a)
+		sb, cb := math.Sincos(b)
+		end := on(cb, sb)
+		if i == n-1 {
+			end = pt
+		}
+		p.CubicTo(on(ca-k*sa, sa+k*ca), on(cb+k*sb, sb-k*cb), end)
+	}
+}
+
+// Close closes the current subpath with a line back to its start. Drawing
+// carries on from there in a new subpath.
+func (p *Path) Close() {
+	if p.begun {
+		p.ops = append(p.ops, pathOp{kind: opClose})
+		p.current = p.start
+	}
+}
+
+// Stroke strokes each subpath of p with s, closed subpaths as polygons.
+func (p *Path) Stroke(dst gfx.Drawer, s *Stroke, c color.Color) {
+	p.subpaths(func(pts []Point, closed bool) {
+		if closed {
+			s.Polygon(dst, pts, c)
+		} else {
+			s.Polyline(dst, pts, c)
+		}
+	})
+}
+
+// subpaths calls fn with each subpath, flattened, and whether it is closed.
+// The points are only valid during the call.
+func (p *Path) subpaths(fn func(pts []Point, closed bool)) {
+	tolerance := p.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	var pts []Point
+	flush := func(closed bool) {
+		if len(pts) > 0 {
+			fn(pts, closed)
+		}
+		pts = pts[:0]
+	}
+
+	var start Point
+	for _, op := range p.ops {
+		if len(pts) == 0 && op.kind != opMove && op.kind != opClose {
+			// drawing on after a Close starts again from where it closed
+			pts = append(pts, start)
+		}
+		switch op.kind {
+		case opMove:
+			flush(false)
+			start = op.p[0]
+			pts = append(pts, start)
+		case opLine:
+			pts = append(pts, op.p[0])
+		case opQuad:
+			pts = flattenQuad(pts, pts[len(pts)-1], op.p[0], op.p[1], tolerance, 0)
+		case opCubic:
+			pts = flattenCubic(pts, pts[len(pts)-1], op.p[0], op.p[1], op.p[2], tolerance, 0)
+		case opClose:
+			flush(true)
+		}
+	}
+	flush(false)
+}
+
+// flattenQuad appends to pts the ends of lines approximating the quadratic
+// Bezier curve from p0 to p2 with control point c, leaving out p0. The curve is
+// split in half until each piece is within tolerance pixels of a straight line,
+// so gentle curves take few lines and tight ones many.
+func flattenQuad(pts []Point, p0, c, p2 Point, tolerance float64, depth int) []Point {
+	// the curve strays from its chord by at most half as far as c does, as
+	// moving c onto the chord would move the curve onto it too
+	if depth == maxFlattenDepth || distance(c, p0, p2)/2 <= tolerance {
+		return append(pts, p2)
+	}
+	c0, c1 := mid(p0, c), mid(c, p2)
+	m := mid(c0, c1)
+	pts = flattenQuad(pts, p0, c0, m, tolerance, depth+1)
+	return flattenQuad(pts, m, c1, p2, tolerance, depth+1)
+}
+
+// flattenCubic is flattenQuad for the cubic Bezier curve from p0 to p3 with
+// control points c1 and c2.
+func flattenCubic(pts []Point, p0, c1, c2, p3 Point, tolerance float64, depth int) []Point {
+	// the curve strays from its chord by at most 3/4 as far as its control
+	// points do
+	if depth == maxFlattenDepth || max(distance(c1, p0, p3), distance(c2, p0, p3))*3/4 <= tolerance {
+		return append(pts, p3)
+	}
+	a, b, cc := mid(p0, c1), mid(c1, c2), mid(c2, p3)
+	ab, bc := mid(a, b), mid(b, cc)
+	m := mid(ab, bc)
+	pts = flattenCubic(pts, p0, a, ab, m, tolerance, depth+1)
+	return flattenCubic(pts, m, bc, cc, p3, tolerance, depth+1)
+}
+
+// distance returns how far p is from the line segment from a to b.
+func distance(p, a, b Point) float64 {
+	d := b.Sub(a)
+	var t float64
+	if l := d.X*d.X + d.Y*d.Y; l != 0 {
+		t = min(max(((p.X-a.X)*d.X+(p.Y-a.Y)*d.Y)/l, 0), 1)
+	}
+	return p.Sub(a.Add(d.Mul(t))).Len()
+}
+
+func mid(a, b Point) Point {
+	return Point{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+}
@@ -0,0 +1,188 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// flatten returns the flattened subpaths of p.
+func flatten(p *Path) [][]Point {
+	var out [][]Point
+	p.subpaths(func(pts []Point, closed bool) {
+		out = append(out, append([]Point(nil), pts...))
+	})
+	return out
+}
+
+func Test_PathCurves(t *testing.T) {
+	var p Path
+	p.MoveTo(Pt(0, 0))
+	p.QuadTo(Pt(10, 20), Pt(20, 0))
+	p.CubicTo(Pt(20, 10), Pt(40, -10), Pt(40, 0))
+	pts := flatten(&p)[0]
+	if pts[0] != Pt(0, 0) || pts[len(pts)-1] != Pt(40, 0) {
+		t.Errorf("flattened from %v to %v", pts[0], pts[len(pts)-1])
+	}
+	// the quad peaks at its midpoint, half way to the control point
+	var lowest float64
+	for _, pt := range pts {
+		if pt.X <= 20 {
+			lowest = max(lowest, pt.Y)
+		}
+	}
+	if math.Abs(lowest-10) > DefaultTolerance {
+		t.Errorf("quad reaches %v", lowest)
+	}
+
+	// a finer tolerance takes more lines
+	p.Tolerance = 0.01
+	if n := len(flatten(&p)[0]); n <= len(pts) {
+		t.Errorf("finer flattening took %d points, was %d", n, len(pts))
+	}
+}
+
+func Test_PathArc(t *testing.T) {
+	tests := []struct {
+		large, sweep bool
+		// the point the arc passes through
+		via Point
+	}{
+		{false, true, Pt(10, 0)},
+		{false, false, Pt(10, 20)},
+	}
+	for _, test := range tests {
+		var p Path
+		p.MoveTo(Pt(0, 10))
+		p.ArcTo(10, 10, 0, test.large, test.sweep, Pt(20, 10))
+		pts := flatten(&p)[0]
+		var nearest float64 = 99
+		for _, pt := range pts {
+			if d := math.Hypot(pt.X-10, pt.Y-10); math.Abs(d-10) > 0.05 {
+				t.Errorf("%v is %v from the center", pt, d)
+			}
+			nearest = min(nearest, pt.Sub(test.via).Len())
+		}
+		if nearest > 0.5 || pts[len(pts)-1] != Pt(20, 10) {
+			t.Errorf("large %v sweep %v: missed %v by %v", test.large, test.sweep, test.via, nearest)
+		}
+	}
+
+	// radii too small are scaled up to a half circle
+	var p Path
+	p.MoveTo(Pt(0, 0))
+	p.ArcTo(1, 1, 0, false, true, Pt(0, 20))
+	for _, pt := range flatten(&p)[0] {
+		if d := math.Hypot(pt.X, pt.Y-10); math.Abs(d-10) > 0.05 {
+			t.Fatalf("%v is %v from the center", pt, d)
+		}
+	}
+}
+
+func Test_PathFill(t *testing.T) {
+	rand.Seed(23)
+	// with integer points, a path fills just as FillPolygon does
+	for i := 0; i < 100; i++ {
+		var pts []image.Point
+		var p Path
+		for j := 0; j < 3+rand.Intn(6); j++ {
+			pt := image.Pt(rand.Intn(40)-4, rand.Intn(40)-4)
+			pts = append(pts, pt)
+			p.LineTo(PointOf(pt))
+		}
+		want := draw(32, 32, func(dst gfx.Drawer) { FillPolygon(dst, pts, color.White) })
+		got := draw(32, 32, func(dst gfx.Drawer) { p.Fill(dst, EvenOdd, color.White) })
+		if !same(got, want) {
+			t.Fatalf("%v filled differently", pts)
+		}
+	}
+
+	// a star's middle is inside it by nonzero, but not by even-odd
+	var star Path
+	for _, pt := range []Point{{10, 0}, {16, 20}, {0, 7}, {20, 7}, {4, 20}} {
+		star.LineTo(pt)
+	}
+	star.Close()
+	nonzero := draw(24, 24, func(dst gfx.Drawer) { star.Fill(dst, NonZero, color.White) })
+	evenodd := draw(24, 24, func(dst gfx.Drawer) { star.Fill(dst, EvenOdd, color.White) })
+	if !nonzero[image.Pt(10, 10)] || evenodd[image.Pt(10, 10)] || !nonzero[image.Pt(10, 4)] || !evenodd[image.Pt(10, 4)] {
+		t.Error("star filled wrong")
+	}
+
+	// two subpaths: a square with a hole wound the other way
+	var ring Path
+	for _, pt := range []Point{{0, 0}, {10, 0}, {10, 10}, {0, 10}} {
+		ring.LineTo(pt)
+	}
+	ring.MoveTo(Pt(3, 3))
+	for _, pt := range []Point{{3, 7}, {7, 7}, {7, 3}} {
+		ring.LineTo(pt)
+	}
+	got := draw(12, 12, func(dst gfx.Drawer) { ring.Fill(dst, NonZero, color.White) })
+	if len(got) != 100-16 || got[image.Pt(4, 4)] {
+		t.Errorf("ring has %d pixels", len(got))
+	}
+}
+
+func Test_PathFillAA(t *testing.T) {
+	var p Path
+	for _, pt := range []Point{{2, 2}, {6.25, 2}, {6.25, 6}, {2, 6}} {
+		p.LineTo(pt)
+	}
+	dst := coverage(8, 8, func(dst *image.RGBA) { p.FillAA(dst, NonZero, color.White) })
+	want := map[image.Point]uint8{{4, 4}: 0xFF, {4, 2}: 0x80, {6, 4}: 0xBF, {6, 2}: 0x60, {2, 4}: 0x80, {1, 4}: 0, {4, 7}: 0}
+	for pt, w := range want {
+		if got := dst.RGBAAt(pt.X, pt.Y).R; got != w {
+			t.Errorf("%v is %d, want %d", pt, got, w)
+		}
+	}
+
+	// a circle's coverage adds up to its area, less what flattening cuts off
+	circle := Path{Tolerance: 0.01}
+	circle.MoveTo(Pt(15.3, 5.1))
+	circle.ArcTo(10, 10, 0, false, true, Pt(15.3, 25.1))
+	circle.ArcTo(10, 10, 0, false, true, Pt(15.3, 5.1))
+	for _, rule := range []FillRule{NonZero, EvenOdd} {
+		dst = coverage(32, 32, func(dst *image.RGBA) { circle.FillAA(dst, rule, color.White) })
+		var sum float64
+		for i := 0; i < len(dst.Pix); i += 4 {
+			sum += float64(dst.Pix[i]) / 0xFF
+		}
+		if math.Abs(sum-100*math.Pi) > 1 {
+			t.Errorf("circle covers %v", sum)
+		}
+	}
+
+	// clipped, it is still a circle
+	dst = coverage(16, 16, func(dst *image.RGBA) { circle.FillAA(dst, NonZero, color.White) })
+	if dst.RGBAAt(15, 15).R != 0xFF || dst.RGBAAt(0, 0).R != 0 {
+		t.Error("clipped circle drawn wrong")
+	}
+
+	// a translucent color is blended over what is there, fully covered or not
+	half := color.NRGBA{0xFF, 0xFF, 0xFF, 0x80}
+	dst = coverage(8, 8, func(dst *image.RGBA) { p.FillAA(dst, NonZero, half) })
+	for pt, w := range map[image.Point]uint8{{4, 4}: 0x80, {3, 3}: 0x80, {4, 2}: 0x40, {1, 4}: 0} {
+		if got := dst.RGBAAt(pt.X, pt.Y); got.R != w || got.A != 0xFF {
+			t.Errorf("translucent %v is %v, want %d", pt, got, w)
+		}
+	}
+}
+
+func Test_PathStroke(t *testing.T) {
+	var p Path
+	p.MoveTo(Pt(2, 2))
+	p.LineTo(Pt(12, 2))
+	p.LineTo(Pt(12, 12))
+	p.Close()
+	p.MoveTo(Pt(20, 2))
+	p.LineTo(Pt(20, 12))
+	got := draw(24, 16, func(dst gfx.Drawer) { p.Stroke(dst, &Stroke{Width: 1}, color.White) })
+	if !got[image.Pt(7, 7)] || !got[image.Pt(20, 7)] || got[image.Pt(16, 7)] {
+		t.Error("path stroked wrong")
+	}
+}
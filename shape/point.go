@@ -0,0 +1,45 @@
+package shape
+
+import (
+	"image"
+	"math"
+)
+
+// Point is a point with sub-pixel precision. Whole coordinates are the centers
+// of pixels, so pixel x, y covers x-0.5 to x+0.5 and y-0.5 to y+0.5, and an
+// image.Point converts to the Point at its pixel's center.
+type Point struct {
+	X, Y float64
+}
+
+// Pt is shorthand for Point{x, y}.
+func Pt(x, y float64) Point {
+	return Point{x, y}
+}
+
+// PointOf returns the Point at the center of pixel p.
+func PointOf(p image.Point) Point {
+	return Point{float64(p.X), float64(p.Y)}
+}
+
+// Round returns the pixel p lies in.
+func (p Point) Round() image.Point {
+	return image.Pt(int(math.Round(p.X)), int(math.Round(p.Y)))
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+func (p Point) Sub(q Point) Point {
+	return Point{p.X - q.X, p.Y - q.Y}
+}
+
+func (p Point) Mul(k float64) Point {
+	return Point{p.X * k, p.Y * k}
+}
+
+// Len returns the distance from the origin to p.
+func (p Point) Len() float64 {
+	return math.Hypot(p.X, p.Y)
+}
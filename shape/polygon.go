@@ -0,0 +1,76 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"slices"
+
+	"github.com/sparques/gfx"
+)
+
+// Polyline draws Lines joining each point in pts to the next.
+func Polyline(dst gfx.Drawer, pts []image.Point, c color.Color) {
+	if len(pts) == 1 {
+		Line(dst, pts[0], pts[0], c)
+	}
+	for i := 1; i < len(pts); i++ {
+		Line(dst, pts[i-1], pts[i], c)
+	}
+}
+
+// Polygon draws the outline of the polygon pts: a Polyline closed back to its
+// first point.
+func Polygon(dst gfx.Drawer, pts []image.Point, c color.Color) {
+	Polyline(dst, pts, c)
+	if len(pts) > 2 {
+		Line(dst, pts[len(pts)-1], pts[0], c)
+	}
+}
+
+// FillPolygon fills the polygon pts, which may be concave or cross itself,
+// with the even-odd rule: a pixel is filled if a ray from its center crosses
+// the outline an odd number of times. Vertices are taken as pixel centers, so
+// a pixel whose center lies on the left or top edge is filled and on the
+// right or bottom edge is not; polygons sharing an edge do not overlap.
+func FillPolygon(dst gfx.Drawer, pts []image.Point, c color.Color) {
+	if len(pts) < 3 {
+		return
+	}
+
+	top, bottom := pts[0].Y, pts[0].Y
+	for _, p := range pts {
+		top, bottom = min(top, p.Y), max(bottom, p.Y)
+	}
+	b := dst.Bounds()
+	top, bottom = max(top, b.Min.Y), min(bottom, b.Max.Y)
+
+	var xs []int
+	for y := top; y < bottom; y++ {
+		xs = xs[:0]
+		for i, p := range pts {
+			q := pts[(i+1)%len(pts)]
+			if (p.Y <= y) == (q.Y <= y) {
+				continue
+			}
+			// the first pixel at or right of where the edge crosses the row
+			num := (y - p.Y) * (q.X - p.X)
+			den := q.Y - p.Y
+			xs = append(xs, p.X+ceilDiv(num, den))
+		}
+		slices.Sort(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			span(dst, image.Rect(xs[i], y, xs[i+1], y+1), c)
+		}
+	}
+}
+
+// ceilDiv returns a/b rounded towards positive infinity.
+func ceilDiv(a, b int) int {
+	if b < 0 {
+		a, b = -a, -b
+	}
+	if a >= 0 {
+		return (a + b - 1) / b
+	}
+	return -(-a / b)
+}
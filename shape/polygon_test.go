@@ -0,0 +1,58 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+func Test_FillPolygon(t *testing.T) {
+	square := []image.Point{{2, 2}, {6, 2}, {6, 6}, {2, 6}}
+	got := draw(8, 8, func(dst gfx.Drawer) { FillPolygon(dst, square, color.White) })
+	if len(got) != 16 || !got[image.Pt(2, 2)] || got[image.Pt(6, 6)] {
+		t.Errorf("square filled %d pixels", len(got))
+	}
+
+	// two triangles splitting a square cover it exactly once
+	a := []image.Point{{0, 0}, {12, 0}, {12, 9}}
+	b := []image.Point{{0, 0}, {12, 9}, {0, 9}}
+	ta := draw(16, 16, func(dst gfx.Drawer) { FillPolygon(dst, a, color.White) })
+	tb := draw(16, 16, func(dst gfx.Drawer) { FillPolygon(dst, b, color.White) })
+	for p := range ta {
+		if tb[p] {
+			t.Fatalf("triangles overlap at %v", p)
+		}
+	}
+	if len(ta)+len(tb) != 12*9 {
+		t.Errorf("triangles cover %d pixels", len(ta)+len(tb))
+	}
+
+	// a self-crossing star leaves its middle empty under even-odd
+	star := []image.Point{{10, 0}, {16, 20}, {0, 7}, {20, 7}, {4, 20}}
+	got = draw(24, 24, func(dst gfx.Drawer) { FillPolygon(dst, star, color.White) })
+	if got[image.Pt(10, 10)] || !got[image.Pt(10, 4)] || !got[image.Pt(3, 8)] {
+		t.Error("star filled wrong")
+	}
+
+	// clipped to the destination
+	got = draw(8, 8, func(dst gfx.Drawer) {
+		FillPolygon(dst, []image.Point{{-10, -10}, {20, -10}, {20, 20}, {-10, 20}}, color.White)
+	})
+	if len(got) != 64 {
+		t.Errorf("big square filled %d pixels", len(got))
+	}
+}
+
+func Test_Polygon(t *testing.T) {
+	tri := []image.Point{{1, 1}, {9, 1}, {1, 9}}
+	got := draw(12, 12, func(dst gfx.Drawer) { Polygon(dst, tri, color.White) })
+	// three lines of 9 pixels sharing their corners
+	if len(got) != 3*9-3 || !got[image.Pt(5, 5)] || got[image.Pt(3, 3)] {
+		t.Errorf("triangle has %d pixels", len(got))
+	}
+	if got := draw(12, 12, func(dst gfx.Drawer) { Polyline(dst, tri, color.White) }); len(got) != 2*9-1 {
+		t.Errorf("polyline has %d pixels", len(got))
+	}
+}
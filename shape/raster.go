@@ -0,0 +1,195 @@
+package shape
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/sparques/gfx"
+)
+
+// FillRule decides which parts of a path that crosses itself are inside it.
+type FillRule int
+
+const (
+	// NonZero fills wherever the path winds around a point a nonzero number of
+	// times, counting clockwise turns against counterclockwise ones.
+	NonZero FillRule = iota
+	// EvenOdd fills wherever a ray from a point crosses the path an odd number
+	// of times.
+	EvenOdd
+)
+
+// aaSubsamples is how many scanlines each row of pixels is sampled at when
+// filling with anti-aliasing.
+const aaSubsamples = 8
+
+// Fill fills p by rule, drawing each pixel whose center is inside it; as with
+// FillPolygon, a center on a top or left edge counts as inside. Open subpaths
+// are closed with a line back to their start.
+//
+// The path is filled a row at a time, with each run of pixels drawn as one
+// span. Beyond the path's own lines, the memory used is a few numbers per line
+// crossing the row.
+func (p *Path) Fill(dst gfx.Drawer, rule FillRule, c color.Color) {
+	b := dst.Bounds()
+	r := newRasterizer(p)
+	// rows whose centers are from the top of the path to just above its bottom
+	top, bottom := max(ceil(r.top), b.Min.Y), min(ceil(r.bottom), b.Max.Y)
+	for y := top; y < bottom; y++ {
+		r.spans(float64(y), rule, func(x0, x1 float64) {
+			span(dst, image.Rect(ceil(x0), y, ceil(x1), y+1), c)
+		})
+	}
+}
+
+// FillAA fills p like Fill, but anti-aliased: each pixel is drawn in
+// proportion to how much of it is inside the path, found by sampling each row
+// at several heights and measuring exactly across it. Pixels are blended over
+// dst with gfx.Blend, except that if c is opaque, fully covered runs of pixels
+// are drawn as spans.
+//
+// On top of what Fill uses, it needs a row of coverage as wide as dst.
+func (p *Path) FillAA(dst gfx.Drawer, rule FillRule, c color.Color) {
+	b := dst.Bounds()
+	r := newRasterizer(p)
+	// rows reaching within half a pixel of the path
+	top, bottom := max(ceil(r.top-0.5), b.Min.Y), min(ceil(r.bottom+0.5), b.Max.Y)
+	coverage := make([]float64, b.Dx())
+	// a span replaces what is there, which only matches blending if c is opaque
+	_, _, _, ca := c.RGBA()
+	opaque := ca == 0xFFFF
+
+	for y := top; y < bottom; y++ {
+		for i := 0; i < aaSubsamples; i++ {
+			sy := float64(y) - 0.5 + (float64(i)+0.5)/aaSubsamples
+			r.spans(sy, rule, func(x0, x1 float64) {
+				// measure from the pixels' left edges, clipped to dst
+				x0 = min(max(x0+0.5, float64(b.Min.X)), float64(b.Max.X)) - float64(b.Min.X)
+				x1 = min(max(x1+0.5, float64(b.Min.X)), float64(b.Max.X)) - float64(b.Min.X)
+				if x0 >= x1 {
+					return
+				}
+				first, last := int(x0), int(x1)
+				if first == last {
+					coverage[first] += (x1 - x0) / aaSubsamples
+					return
+				}
+				coverage[first] += (float64(first+1) - x0) / aaSubsamples
+				for x := first + 1; x < last; x++ {
+					coverage[x] += 1.0 / aaSubsamples
+				}
+				if last < len(coverage) {
+					coverage[last] += (x1 - float64(last)) / aaSubsamples
+				}
+			})
+		}
+
+		for x := 0; x < len(coverage); x++ {
+			a := uint8(min(coverage[x], 1)*0xFF + 0.5)
+			if a == 0xFF && opaque {
+				end := x + 1
+				for end < len(coverage) && coverage[end] >= 1-0.5/0xFF {
+					end++
+				}
+				span(dst, image.Rect(b.Min.X+x, y, b.Min.X+end, y+1), c)
+				clear(coverage[x:end])
+				x = end - 1
+				continue
+			}
+			gfx.Blend(dst, b.Min.X+x, y, c, a)
+			coverage[x] = 0
+		}
+	}
+}
+
+// edge is a line of a path, with y0 < y1.
+type edge struct {
+	x0, y0, x1, y1 float64
+	// dir is 1 if the path runs down the edge, -1 if up
+	dir int
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// rasterizer finds where rows cross a path's edges.
+type rasterizer struct {
+	edges []edge
+	// top and bottom are the highest and lowest points of the path
+	top, bottom float64
+	// active are the edges that may cross the current row; as rows go down,
+	// edges are added from next and dropped once passed
+	active    []edge
+	next      int
+	crossings []crossing
+}
+
+func newRasterizer(p *Path) *rasterizer {
+	r := &rasterizer{top: math.Inf(1), bottom: math.Inf(-1)}
+	addEdge := func(a, b Point) {
+		if a.Y == b.Y {
+			return
+		}
+		e := edge{a.X, a.Y, b.X, b.Y, 1}
+		if a.Y > b.Y {
+			e = edge{b.X, b.Y, a.X, a.Y, -1}
+		}
+		r.edges = append(r.edges, e)
+		r.top, r.bottom = min(r.top, e.y0), max(r.bottom, e.y1)
+	}
+	p.subpaths(func(pts []Point, closed bool) {
+		for i := 1; i < len(pts); i++ {
+			addEdge(pts[i-1], pts[i])
+		}
+		addEdge(pts[len(pts)-1], pts[0])
+	})
+	slices.SortFunc(r.edges, func(a, b edge) int { return cmp.Compare(a.y0, b.y0) })
+	return r
+}
+
+// spans calls fn with each span of the scanline at y inside the path by rule.
+// Scanlines must be visited from the top down.
+func (r *rasterizer) spans(y float64, rule FillRule, fn func(x0, x1 float64)) {
+	for r.next < len(r.edges) && r.edges[r.next].y0 <= y {
+		r.active = append(r.active, r.edges[r.next])
+		r.next++
+	}
+	r.active = slices.DeleteFunc(r.active, func(e edge) bool { return e.y1 <= y })
+
+	r.crossings = r.crossings[:0]
+	for _, e := range r.active {
+		x := e.x0 + (y-e.y0)*(e.x1-e.x0)/(e.y1-e.y0)
+		r.crossings = append(r.crossings, crossing{x, e.dir})
+	}
+	slices.SortFunc(r.crossings, func(a, b crossing) int { return cmp.Compare(a.x, b.x) })
+
+	inside := func(winding, crossed int) bool {
+		if rule == EvenOdd {
+			return crossed%2 == 1
+		}
+		return winding != 0
+	}
+	var winding int
+	var start float64
+	for i, cr := range r.crossings {
+		was := inside(winding, i)
+		winding += cr.dir
+		switch is := inside(winding, i+1); {
+		case is && !was:
+			start = cr.x
+		case was && !is:
+			fn(start, cr.x)
+		}
+	}
+}
+
+// ceil returns v rounded up to an int, clamped to a range that leaves room to
+// add to it.
+func ceil(v float64) int {
+	return int(min(max(math.Ceil(v), math.MinInt32), math.MaxInt32))
+}
@@ -0,0 +1,68 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/sparques/gfx"
+)
+
+// HLine draws a horizontal line from x0 to x1 inclusive at y.
+func HLine(dst gfx.Drawer, x0, x1, y int, c color.Color) {
+	span(dst, image.Rect(min(x0, x1), y, max(x0, x1)+1, y+1), c)
+}
+
+// VLine draws a vertical line from y0 to y1 inclusive at x.
+func VLine(dst gfx.Drawer, x, y0, y1 int, c color.Color) {
+	span(dst, image.Rect(x, min(y0, y1), x+1, max(y0, y1)+1), c)
+}
+
+// Rect draws the one pixel wide outline of r, along the inside of its edges.
+func Rect(dst gfx.Drawer, r image.Rectangle, c color.Color) {
+	r = r.Canon()
+	if r.Empty() {
+		return
+	}
+	span(dst, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+1), c)
+	if r.Dy() > 1 {
+		span(dst, image.Rect(r.Min.X, r.Max.Y-1, r.Max.X, r.Max.Y), c)
+	}
+	if r.Dy() > 2 {
+		span(dst, image.Rect(r.Min.X, r.Min.Y+1, r.Min.X+1, r.Max.Y-1), c)
+		span(dst, image.Rect(r.Max.X-1, r.Min.Y+1, r.Max.X, r.Max.Y-1), c)
+	}
+}
+
+// FillRect fills r. Unlike gfx.Fill, it does not rely on dst's Fill to clip.
+func FillRect(dst gfx.Drawer, r image.Rectangle, c color.Color) {
+	span(dst, r.Canon(), c)
+}
+
+// RoundRect draws the one pixel wide outline of r with its corners rounded to
+// quarter circles of the given radius, which is limited to half of r's
+// smaller side.
+func RoundRect(dst gfx.Drawer, r image.Rectangle, radius int, c color.Color) {
+	x0, y0, x1, y1, radius, ok := roundCorners(r, radius)
+	if ok {
+		roundedOutline(dst, x0, y0, x1, y1, radius, radius, c)
+	}
+}
+
+// FillRoundRect fills r with its corners rounded as RoundRect does.
+func FillRoundRect(dst gfx.Drawer, r image.Rectangle, radius int, c color.Color) {
+	x0, y0, x1, y1, radius, ok := roundCorners(r, radius)
+	if ok {
+		roundedFill(dst, x0, y0, x1, y1, radius, radius, c)
+	}
+}
+
+// roundCorners returns the centers of the top left and bottom right corners of
+// r rounded with radius, and the radius limited to fit.
+func roundCorners(r image.Rectangle, radius int) (x0, y0, x1, y1, rad int, ok bool) {
+	r = r.Canon()
+	if r.Empty() {
+		return 0, 0, 0, 0, 0, false
+	}
+	rad = max(min(radius, (r.Dx()-1)/2, (r.Dy()-1)/2), 0)
+	return r.Min.X + rad, r.Min.Y + rad, r.Max.X - 1 - rad, r.Max.Y - 1 - rad, rad, true
+}
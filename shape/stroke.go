@@ -0,0 +1,244 @@
+package shape
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/sparques/gfx"
+)
+
+// Cap is the shape drawn at the open ends of a stroke.
+type Cap int
+
+const (
+	// ButtCap ends the stroke square at its end point.
+	ButtCap Cap = iota
+	// RoundCap ends the stroke with a half circle around its end point.
+	RoundCap
+	// SquareCap ends the stroke square, half its width past its end point.
+	SquareCap
+)
+
+// Join is the shape drawn where two lines of a stroke meet.
+type Join int
+
+const (
+	// MiterJoin extends the outer edges of the lines until they meet, unless
+	// that is further than the Stroke's MiterLimit allows, in which case the
+	// join is beveled.
+	MiterJoin Join = iota
+	// RoundJoin rounds the outer corner with a circle around the point.
+	RoundJoin
+	// BevelJoin cuts the outer corner off straight.
+	BevelJoin
+)
+
+// DefaultMiterLimit is the MiterLimit used when a Stroke's is zero.
+const DefaultMiterLimit = 4
+
+// Stroke describes how to draw a line of some width along a polyline. It is
+// drawn as a set of convex pieces (one for each line, join and cap) each
+// filled a row at a time, so destinations that are a gfx.Filler fill the
+// rows natively. A pixel is drawn if its center is within the stroke.
+type Stroke struct {
+	// Width is the width of the stroke in pixels.
+	Width float64
+	// Cap is drawn at the ends of open polylines and of each dash.
+	Cap Cap
+	// Join is drawn where lines meet.
+	Join Join
+	// MiterLimit is the longest a miter may be, as a multiple of Width,
+	// before it is beveled instead. Zero means DefaultMiterLimit.
+	MiterLimit float64
+	// Dash, if not empty, breaks the stroke into dashes: alternating lengths
+	// drawn and skipped, starting with a drawn one. An odd number of lengths
+	// is repeated to make an even number.
+	Dash []float64
+	// DashOffset is how far into the Dash pattern the stroke starts.
+	DashOffset float64
+}
+
+// Polyline strokes the lines joining each point in pts to the next.
+func (s *Stroke) Polyline(dst gfx.Drawer, pts []Point, c color.Color) {
+	for _, dash := range s.dashes(pts) {
+		s.draw(dst, dash, false, c)
+	}
+}
+
+// Polygon strokes the polygon pts, closed back to its first point; unless it
+// is dashed, it has a join there rather than caps.
+func (s *Stroke) Polygon(dst gfx.Drawer, pts []Point, c color.Color) {
+	if len(pts) > 1 && s.dashed() {
+		s.Polyline(dst, append(pts[:len(pts):len(pts)], pts[0]), c)
+		return
+	}
+	s.draw(dst, pts, true, c)
+}
+
+// draw strokes pts without dashes.
+func (s *Stroke) draw(dst gfx.Drawer, pts []Point, closed bool, c color.Color) {
+	// lines of no length have no direction to draw them in
+	var ps []Point
+	for _, p := range pts {
+		if len(ps) == 0 || p != ps[len(ps)-1] {
+			ps = append(ps, p)
+		}
+	}
+	if closed && len(ps) > 1 && ps[0] == ps[len(ps)-1] {
+		ps = ps[:len(ps)-1]
+	}
+	if len(ps) == 0 {
+		return
+	}
+
+	hw := s.Width / 2
+	if len(ps) == 1 {
+		// a dot has caps only, facing any which way
+		if !closed {
+			s.cap(dst, ps[0], Pt(1, 0), hw, c)
+			s.cap(dst, ps[0], Pt(-1, 0), hw, c)
+		}
+		return
+	}
+
+	n := len(ps)
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+	dir := func(i int) Point {
+		d := ps[(i+1)%n].Sub(ps[i])
+		return d.Mul(1 / d.Len())
+	}
+
+	for i := 0; i < segments; i++ {
+		a, b := ps[i], ps[(i+1)%n]
+		d := dir(i)
+		off := Pt(-d.Y, d.X).Mul(hw)
+		fillConvex(dst, []Point{a.Add(off), b.Add(off), b.Sub(off), a.Sub(off)}, c)
+	}
+
+	for i := 1; i < segments; i++ {
+		s.join(dst, ps[i], dir(i-1), dir(i), hw, c)
+	}
+	if closed {
+		s.join(dst, ps[0], dir(n-1), dir(0), hw, c)
+		return
+	}
+	s.cap(dst, ps[0], dir(0).Mul(-1), hw, c)
+	s.cap(dst, ps[n-1], dir(n-2), hw, c)
+}
+
+// join draws the join at v between a line heading d0 and the next heading d1.
+func (s *Stroke) join(dst gfx.Drawer, v, d0, d1 Point, hw float64, c color.Color) {
+	if s.Join == RoundJoin {
+		disc(dst, v, hw, c)
+		return
+	}
+
+	cross := d0.X*d1.Y - d0.Y*d1.X
+	if cross == 0 {
+		// straight on, or doubling back, where a miter would never end
+		return
+	}
+	// the outer corner is on the side the path turns away from
+	side := -math.Copysign(hw, cross)
+	n0, n1 := Pt(-d0.Y, d0.X).Mul(side), Pt(-d1.Y, d1.X).Mul(side)
+
+	if s.Join == MiterJoin {
+		limit := s.MiterLimit
+		if limit == 0 {
+			limit = DefaultMiterLimit
+		}
+		// the miter is 1/cos(φ/2) times the width, for φ the angle turned
+		dot := d0.X*d1.X + d0.Y*d1.Y
+		if 2/(1+dot) <= limit*limit {
+			tip := v.Add(n0.Add(n1).Mul(1 / (1 + dot)))
+			fillConvex(dst, []Point{v, v.Add(n0), tip, v.Add(n1)}, c)
+			return
+		}
+	}
+	fillConvex(dst, []Point{v, v.Add(n0), v.Add(n1)}, c)
+}
+
+// cap draws the cap at end p of a line heading out of it in direction d.
+func (s *Stroke) cap(dst gfx.Drawer, p, d Point, hw float64, c color.Color) {
+	switch s.Cap {
+	case RoundCap:
+		disc(dst, p, hw, c)
+	case SquareCap:
+		off, ahead := Pt(-d.Y, d.X).Mul(hw), d.Mul(hw)
+		fillConvex(dst, []Point{p.Add(off), p.Add(off).Add(ahead), p.Sub(off).Add(ahead), p.Sub(off)}, c)
+	}
+}
+
+// dashed reports whether the stroke has a usable dash pattern.
+func (s *Stroke) dashed() bool {
+	var total float64
+	for _, l := range s.Dash {
+		if l < 0 {
+			return false
+		}
+		total += l
+	}
+	return total > 0
+}
+
+// dashes breaks pts into the polylines of each dash, or returns it whole if
+// the stroke is not dashed.
+func (s *Stroke) dashes(pts []Point) [][]Point {
+	if !s.dashed() || len(pts) < 2 {
+		return [][]Point{pts}
+	}
+	pattern := s.Dash
+	if len(pattern)%2 == 1 {
+		pattern = append(pattern[:len(pattern):len(pattern)], pattern...)
+	}
+	var total float64
+	for _, l := range pattern {
+		total += l
+	}
+
+	// find where in the pattern the offset starts us
+	i, remaining := 0, pattern[0]
+	for offset := math.Mod(math.Mod(s.DashOffset, total)+total, total); offset > 0; {
+		if offset < remaining {
+			remaining -= offset
+			break
+		}
+		offset -= remaining
+		i = (i + 1) % len(pattern)
+		remaining = pattern[i]
+	}
+
+	var out [][]Point
+	var dash []Point
+	if i%2 == 0 {
+		dash = []Point{pts[0]}
+	}
+	for j := 1; j < len(pts); j++ {
+		a, b := pts[j-1], pts[j]
+		length := b.Sub(a).Len()
+		var t float64
+		for length-t > remaining {
+			t += remaining
+			p := a.Add(b.Sub(a).Mul(t / length))
+			if i%2 == 0 {
+				out = append(out, append(dash, p))
+				dash = nil
+			} else {
+				dash = []Point{p}
+			}
+			i = (i + 1) % len(pattern)
+			remaining = pattern[i]
+		}
+		remaining -= length - t
+		if i%2 == 0 {
+			dash = append(dash, b)
+		}
+	}
+	if i%2 == 0 && len(dash) > 0 {
+		out = append(out, dash)
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package shape
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+func Test_StrokeCaps(t *testing.T) {
+	line := []Point{{2, 10}, {12, 10}}
+	stroke := func(cp Cap) map[image.Point]bool {
+		return draw(16, 20, func(dst gfx.Drawer) { (&Stroke{Width: 4, Cap: cp}).Polyline(dst, line, color.White) })
+	}
+
+	butt := stroke(ButtCap)
+	if len(butt) != 10*4 || !butt[image.Pt(2, 8)] || !butt[image.Pt(11, 11)] || butt[image.Pt(12, 10)] || butt[image.Pt(2, 12)] {
+		t.Errorf("butt stroke has %d pixels", len(butt))
+	}
+	if square := stroke(SquareCap); len(square) != 14*4 || !square[image.Pt(0, 8)] || !square[image.Pt(13, 11)] {
+		t.Errorf("square stroke has %d pixels", len(square))
+	}
+	round := stroke(RoundCap)
+	if !round[image.Pt(0, 10)] || !round[image.Pt(14, 10)] || round[image.Pt(0, 8)] || round[image.Pt(15, 10)] {
+		t.Error("round caps drawn wrong")
+	}
+
+	// a dot has only caps
+	dot := draw(8, 8, func(dst gfx.Drawer) { (&Stroke{Width: 3, Cap: SquareCap}).Polyline(dst, []Point{{4, 4}}, color.White) })
+	if len(dot) != 9 {
+		t.Errorf("square dot has %d pixels", len(dot))
+	}
+}
+
+func Test_StrokeJoins(t *testing.T) {
+	corner := []Point{{2, 4}, {12, 4}, {12, 14}}
+	stroke := func(s Stroke) map[image.Point]bool {
+		s.Width = 4
+		return draw(20, 20, func(dst gfx.Drawer) { s.Polyline(dst, corner, color.White) })
+	}
+
+	miter, bevel, round := stroke(Stroke{Join: MiterJoin}), stroke(Stroke{Join: BevelJoin}), stroke(Stroke{Join: RoundJoin})
+	if !miter[image.Pt(13, 2)] || bevel[image.Pt(13, 2)] || round[image.Pt(13, 2)] {
+		t.Error("outer corner drawn wrong")
+	}
+	if !round[image.Pt(13, 3)] || miter[image.Pt(14, 2)] {
+		t.Error("round join drawn wrong")
+	}
+	for p := range bevel {
+		if !miter[p] || !round[p] {
+			t.Fatalf("%v is beveled but not mitered or rounded", p)
+		}
+	}
+
+	// a sharp turn's miter is long, and beveled once it passes the limit
+	sharp := []Point{{2, 4}, {30, 8}, {2, 12}}
+	long := draw(64, 16, func(dst gfx.Drawer) { (&Stroke{Width: 4, MiterLimit: 10}).Polyline(dst, sharp, color.White) })
+	short := draw(64, 16, func(dst gfx.Drawer) { (&Stroke{Width: 4}).Polyline(dst, sharp, color.White) })
+	if !long[image.Pt(40, 8)] || short[image.Pt(34, 8)] {
+		t.Error("miter limit not applied")
+	}
+
+	// a closed square has joins all the way round
+	square := []Point{{4, 4}, {14, 4}, {14, 14}, {4, 14}}
+	got := draw(20, 20, func(dst gfx.Drawer) { (&Stroke{Width: 2}).Polygon(dst, square, color.White) })
+	if len(got) != 12*12-8*8 {
+		t.Errorf("closed square has %d pixels", len(got))
+	}
+}
+
+func Test_StrokeDash(t *testing.T) {
+	line := []Point{{0, 5}, {20, 5}}
+	columns := func(s *Stroke) string {
+		got := draw(20, 10, func(dst gfx.Drawer) { s.Polyline(dst, line, color.White) })
+		b := make([]byte, 20)
+		for x := range b {
+			b[x] = '.'
+			if got[image.Pt(x, 5)] {
+				b[x] = '#'
+			}
+		}
+		return string(b)
+	}
+
+	tests := []struct {
+		stroke Stroke
+		want   string
+	}{
+		{Stroke{Width: 2}, "####################"},
+		{Stroke{Width: 2, Dash: []float64{4, 2}}, "####..####..####..##"},
+		{Stroke{Width: 2, Dash: []float64{4, 2}, DashOffset: 1}, "###..####..####..###"},
+		{Stroke{Width: 2, Dash: []float64{4, 2}, DashOffset: -2}, "..####..####..####.."},
+		{Stroke{Width: 2, Dash: []float64{3}}, "###...###...###...##"},
+		{Stroke{Width: 2, Dash: []float64{0, 4}, Cap: SquareCap}, "#..##..##..##..##..."},
+	}
+	for _, test := range tests {
+		if got := columns(&test.stroke); got != test.want {
+			t.Errorf("%+v: got %s, want %s", test.stroke, got, test.want)
+		}
+	}
+
+	// dashes carry on round corners
+	s := &Stroke{Width: 1, Dash: []float64{5, 5}, DashOffset: 3}
+	dashes := s.dashes([]Point{{0, 0}, {4, 0}, {4, 10}})
+	want := [][]Point{{{0, 0}, {2, 0}}, {{4, 3}, {4, 8}}}
+	if len(dashes) != len(want) {
+		t.Fatalf("got dashes %v", dashes)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if d := dashes[i][j].Sub(want[i][j]); math.Abs(d.X)+math.Abs(d.Y) > 1e-9 {
+				t.Fatalf("got dashes %v, want %v", dashes, want)
+			}
+		}
+	}
+}
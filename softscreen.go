@@ -24,6 +24,10 @@ type SoftScreenOf[PixType color.Color] struct {
 	Cell image.Rectangle
 
 	Convert color.Model
+
+	// ScrollFill decides what happens to the area of the viewport exposed by
+	// Scroll and Pan. By default it shows whatever was already on the canvas.
+	ScrollFill ScrollFill
 }
 
 func NewSoftScreen(cell, viewport, canvas image.Rectangle) *SoftScreen {
@@ -88,20 +92,23 @@ func (s *SoftScreenOf[PixType]) Set(x, y int, c color.Color) {
 		x += s.Canvas.Dx()
 	}
 
-	if native, ok := c.(PixType); ok {
+	// Silently fail. Is there not a better option?
+	if native, ok := s.native(c); ok {
 		s.Pix[y*s.Canvas.Dx()+x] = native
-		return
 	}
+}
 
-	// otherwise attempt conversion;
-	// Silently fail. Is there not a better option?
-	if s.Convert == nil {
-		return
+// native returns c as a PixType, converting it with Convert if need be.
+func (s *SoftScreenOf[PixType]) native(c color.Color) (PixType, bool) {
+	if native, ok := c.(PixType); ok {
+		return native, true
 	}
-	if native, ok := s.Convert.Convert(c).(PixType); ok {
-		s.Pix[y*s.Canvas.Dx()+x] = native
+	if s.Convert == nil {
+		var zero PixType
+		return zero, false
 	}
-
+	native, ok := s.Convert.Convert(c).(PixType)
+	return native, ok
 }
 
 func (s *SoftScreenOf[PixType]) Bounds() image.Rectangle {
@@ -127,6 +134,8 @@ func (s *SoftScreenOf[PixType]) Scroll(amount int) {
 // Pan shifts the viewport around. x and y are in pixels.
 func (s *SoftScreenOf[PixType]) Pan(x, y int) {
 	s.Viewport = s.Viewport.Add(image.Point{x, y})
+	// the viewport may be shifted back over the canvas below, so wait till then
+	defer func() { s.ScrollFill.apply(s, s.Viewport, image.Point{x, y}) }()
 
 	// return if scrolling has not caused Viewport to stop intersecting with Canvas
 	if !s.Viewport.Overlaps(s.Canvas) {
@@ -157,12 +166,209 @@ func (s *SoftScreenOf[PixType]) SetViewport(pt image.Point) {
 	s.Viewport = s.Viewport.Sub(s.Viewport.Min).Add(pt)
 }
 
+// Blit implements gfx.Blitter. Like Set, it wraps rather than clipping to the
+// viewport. A same-typed SoftScreenOf source is copied a row span at a time;
+// anything else is converted pixel by pixel.
 func (s *SoftScreenOf[PixType]) Blit(src image.Image, at image.Point) {
-	blit(s, src, at)
+	sp := src.Bounds().Min
+	destRect := s.clip(src.Bounds().Sub(sp).Add(at))
+	if destRect.Empty() {
+		return
+	}
+
+	if ss, ok := src.(*SoftScreenOf[PixType]); ok {
+		destRect = ss.clip(destRect)
+		var row []PixType
+		if ss == s {
+			// rows of the source may be overwritten before they are read
+			row = make([]PixType, destRect.Dx())
+		}
+		for y := 0; y < destRect.Dy(); y++ {
+			sy := y
+			if ss == s && sp.Y < destRect.Min.Y {
+				sy = destRect.Dy() - 1 - y
+			}
+			if row != nil {
+				ss.readSpan(row, sp.X, sp.Y+sy)
+				s.writeSpan(row, destRect.Min.X, destRect.Min.Y+sy)
+				continue
+			}
+			// copy between the pieces of both wrapped spans
+			for x, n := 0, destRect.Dx(); n > 0; {
+				sa, _ := ss.span(sp.X+x, sp.Y+sy, n)
+				da, _ := s.span(destRect.Min.X+x, destRect.Min.Y+sy, n)
+				c := copy(da, sa)
+				x, n = x+c, n-c
+			}
+		}
+		return
+	}
+
+	for y := 0; y < destRect.Dy(); y++ {
+		a, b := s.span(destRect.Min.X, destRect.Min.Y+y, destRect.Dx())
+		for i := range a {
+			if native, ok := s.native(src.At(sp.X+i, sp.Y+y)); ok {
+				a[i] = native
+			}
+		}
+		for i := range b {
+			if native, ok := s.native(src.At(sp.X+len(a)+i, sp.Y+y)); ok {
+				b[i] = native
+			}
+		}
+	}
 }
 
+// Fill implements gfx.Filler. The color is converted once, then each row span
+// of rect is filled, split in two where it wraps around the canvas.
 func (s *SoftScreenOf[PixType]) Fill(rect image.Rectangle, c color.Color) {
-	fill(s, rect, c)
+	rect = s.clip(rect.Intersect(s.Viewport))
+	if rect.Empty() {
+		return
+	}
+
+	if s.Convert != nil {
+		c = s.Convert.Convert(c)
+	}
+	native, ok := s.native(c)
+	if !ok {
+		return
+	}
+
+	// fill the first row, then copy it into the rest
+	a, b := s.span(rect.Min.X, rect.Min.Y, rect.Dx())
+	for i := range a {
+		a[i] = native
+	}
+	for i := range b {
+		b[i] = native
+	}
+	for y := rect.Min.Y + 1; y < rect.Max.Y; y++ {
+		da, db := s.span(rect.Min.X, y, rect.Dx())
+		copy(da, a)
+		copy(db, b)
+	}
+}
+
+// RegionScroll implements gfx.RegionScroller. Positive amounts move the region's
+// contents up; the vacated rows are left as is unless ScrollFill says otherwise.
+func (s *SoftScreenOf[PixType]) RegionScroll(region image.Rectangle, amount int) {
+	region = s.clip(region.Intersect(s.Viewport))
+	if region.Empty() || amount == 0 {
+		return
+	}
+	defer s.ScrollFill.apply(s, region, image.Pt(0, amount))
+
+	if amount >= region.Dy() || -amount >= region.Dy() {
+		return
+	}
+
+	// Rows only move vertically, so the pieces of each span line up.
+	move := func(y int) {
+		da, db := s.span(region.Min.X, y, region.Dx())
+		sa, sb := s.span(region.Min.X, y+amount, region.Dx())
+		copy(da, sa)
+		copy(db, sb)
+	}
+	if amount > 0 {
+		for y := region.Min.Y; y < region.Max.Y-amount; y++ {
+			move(y)
+		}
+		return
+	}
+	for y := region.Max.Y - 1; y >= region.Min.Y-amount; y-- {
+		move(y)
+	}
+}
+
+// VectorScroll implements gfx.VectorScroller. Unless ScrollFill says otherwise,
+// pixels scrolled off one edge of region wrap around to the opposite edge.
+func (s *SoftScreenOf[PixType]) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = s.clip(region.Intersect(s.Viewport))
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	defer s.ScrollFill.apply(s, region, vector)
+
+	// rotating by k is reversing the first k, reversing the rest, then reversing the lot
+	if k := mod(vector.X, region.Dx()); k != 0 {
+		for y := region.Min.Y; y < region.Max.Y; y++ {
+			a, b := s.span(region.Min.X, y, region.Dx())
+			reverseSpan(a, b, 0, k)
+			reverseSpan(a, b, k, region.Dx())
+			reverseSpan(a, b, 0, region.Dx())
+		}
+	}
+	if k := mod(vector.Y, region.Dy()); k != 0 {
+		s.reverseRows(region, region.Min.Y, region.Min.Y+k)
+		s.reverseRows(region, region.Min.Y+k, region.Max.Y)
+		s.reverseRows(region, region.Min.Y, region.Max.Y)
+	}
+}
+
+// reverseRows reverses the order of rows [from, to) within region's columns.
+func (s *SoftScreenOf[PixType]) reverseRows(region image.Rectangle, from, to int) {
+	for to--; from < to; from, to = from+1, to-1 {
+		fa, fb := s.span(region.Min.X, from, region.Dx())
+		ta, tb := s.span(region.Min.X, to, region.Dx())
+		for i := range fa {
+			fa[i], ta[i] = ta[i], fa[i]
+		}
+		for i := range fb {
+			fb[i], tb[i] = tb[i], fb[i]
+		}
+	}
+}
+
+// reverseSpan reverses elements [from, to) of the span made up of a followed by b.
+func reverseSpan[E any](a, b []E, from, to int) {
+	at := func(i int) *E {
+		if i < len(a) {
+			return &a[i]
+		}
+		return &b[i-len(a)]
+	}
+	for to--; from < to; from, to = from+1, to-1 {
+		x, y := at(from), at(to)
+		*x, *y = *y, *x
+	}
+}
+
+// span returns the n pixels starting at x, y as they lie in Pix: a, and if the
+// run wraps past the right edge of the canvas, the rest in b. n must not exceed
+// the canvas width.
+func (s *SoftScreenOf[PixType]) span(x, y, n int) (a, b []PixType) {
+	w := s.Canvas.Dx()
+	row := s.Pix[mod(y, s.Canvas.Dy())*w:][:w]
+	x = mod(x, w)
+	if x+n <= w {
+		return row[x : x+n], nil
+	}
+	return row[x:], row[:x+n-w]
+}
+
+// readSpan copies the pixels starting at x, y into dst.
+func (s *SoftScreenOf[PixType]) readSpan(dst []PixType, x, y int) {
+	a, b := s.span(x, y, len(dst))
+	copy(dst[copy(dst, a):], b)
+}
+
+// writeSpan copies src into the pixels starting at x, y.
+func (s *SoftScreenOf[PixType]) writeSpan(src []PixType, x, y int) {
+	a, b := s.span(x, y, len(src))
+	copy(b, src[copy(a, src):])
+}
+
+// clip limits r to at most the size of the canvas, so that no pixel is covered
+// twice by wrapping.
+func (s *SoftScreenOf[PixType]) clip(r image.Rectangle) image.Rectangle {
+	if r.Dx() > s.Canvas.Dx() {
+		r.Max.X = r.Min.X + s.Canvas.Dx()
+	}
+	if r.Dy() > s.Canvas.Dy() {
+		r.Max.Y = r.Min.Y + s.Canvas.Dy()
+	}
+	return r
 }
 
 type cell[PixType color.Color] struct {
@@ -0,0 +1,14 @@
+package term // github.com/sparques/gfx/term
+/*
+Package term is a VT100/ANSI terminal emulator which draws onto a gfx screen.
+
+A Terminal is an io.Writer: write text and escape sequences to it and it renders
+glyphs into the cells of its Screen using a Font. gfx.SoftScreenOf makes a good
+Screen, since scrolling the whole screen is then just a matter of panning its
+viewport.
+
+Supported are the usual control characters; cursor movement; erasing in the
+display and line; inserting and deleting lines; scroll regions; saving and
+restoring the cursor; and SGR colors, including the 256 color palette and
+truecolor. Other sequences are parsed and ignored.
+*/
@@ -0,0 +1,45 @@
+package term
+
+import "image/color"
+
+// XtermPalette is xterm's 256 color palette: the 8 standard and 8 bright colors,
+// a 6x6x6 color cube, then 24 shades of gray. It is what Terminals use unless
+// given another.
+var XtermPalette = xtermPalette()
+
+func xtermPalette() color.Palette {
+	p := color.Palette{
+		color.RGBA{0x00, 0x00, 0x00, 0xFF},
+		color.RGBA{0xCD, 0x00, 0x00, 0xFF},
+		color.RGBA{0x00, 0xCD, 0x00, 0xFF},
+		color.RGBA{0xCD, 0xCD, 0x00, 0xFF},
+		color.RGBA{0x00, 0x00, 0xEE, 0xFF},
+		color.RGBA{0xCD, 0x00, 0xCD, 0xFF},
+		color.RGBA{0x00, 0xCD, 0xCD, 0xFF},
+		color.RGBA{0xE5, 0xE5, 0xE5, 0xFF},
+		color.RGBA{0x7F, 0x7F, 0x7F, 0xFF},
+		color.RGBA{0xFF, 0x00, 0x00, 0xFF},
+		color.RGBA{0x00, 0xFF, 0x00, 0xFF},
+		color.RGBA{0xFF, 0xFF, 0x00, 0xFF},
+		color.RGBA{0x5C, 0x5C, 0xFF, 0xFF},
+		color.RGBA{0xFF, 0x00, 0xFF, 0xFF},
+		color.RGBA{0x00, 0xFF, 0xFF, 0xFF},
+		color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	}
+
+	levels := [6]uint8{0x00, 0x5F, 0x87, 0xAF, 0xD7, 0xFF}
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for b := 0; b < 6; b++ {
+				p = append(p, color.RGBA{levels[r], levels[g], levels[b], 0xFF})
+			}
+		}
+	}
+
+	for i := 0; i < 24; i++ {
+		v := uint8(8 + 10*i)
+		p = append(p, color.RGBA{v, v, v, 0xFF})
+	}
+
+	return p
+}
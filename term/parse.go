@@ -0,0 +1,320 @@
+package term
+
+import "image/color"
+
+type parseState int
+
+const (
+	stateGround parseState = iota
+	stateEscape
+	// stateCharset skips the byte after ESC ( and friends, which pick a character set.
+	stateCharset
+	stateCSI
+	stateOSC
+	// stateOSCEscape is an ESC seen within an OSC string, which ought to be ESC \.
+	stateOSCEscape
+)
+
+// maxParams limits how many CSI parameters are kept.
+const maxParams = 16
+
+// parse feeds a single byte through the escape sequence parser.
+func (t *Terminal) parse(b byte) {
+	switch t.state {
+	case stateGround:
+		if b < 0x20 || b == 0x7F {
+			t.control(b)
+		} else {
+			t.print(rune(b))
+		}
+
+	case stateEscape:
+		t.state = stateGround
+		t.escape(b)
+
+	case stateCharset:
+		t.state = stateGround
+
+	case stateCSI:
+		switch {
+		case b >= '0' && b <= '9':
+			if len(t.params) == 0 {
+				t.params = append(t.params, 0)
+			}
+			if p := &t.params[len(t.params)-1]; *p < 10000 {
+				*p = *p*10 + int(b-'0')
+			}
+		case b == ';' || b == ':':
+			if len(t.params) == 0 {
+				t.params = append(t.params, 0)
+			}
+			if len(t.params) < maxParams {
+				t.params = append(t.params, 0)
+			}
+		case b >= '<' && b <= '?':
+			t.private = b
+		case b >= 0x20 && b <= 0x2F:
+			// intermediate bytes; none of the supported sequences use them
+		case b >= 0x40 && b <= 0x7E:
+			t.state = stateGround
+			t.csi(b)
+		case b == 0x1B:
+			t.state = stateEscape
+		default:
+			t.control(b)
+		}
+
+	case stateOSC:
+		switch b {
+		case 0x07:
+			t.state = stateGround
+		case 0x1B:
+			t.state = stateOSCEscape
+		}
+
+	case stateOSCEscape:
+		if b == '\\' {
+			t.state = stateGround
+		} else {
+			t.state = stateOSC
+		}
+	}
+}
+
+// control carries out a C0 control character.
+func (t *Terminal) control(b byte) {
+	switch b {
+	case '\b':
+		if t.col > 0 {
+			t.col--
+		}
+		t.wrapNext = false
+	case '\t':
+		t.moveTo((t.col/8+1)*8, t.row)
+	case '\n', '\v', '\f':
+		t.lineFeed()
+		if t.CRLF {
+			t.col = 0
+		}
+	case '\r':
+		t.col = 0
+		t.wrapNext = false
+	case 0x1B:
+		t.state = stateEscape
+	}
+}
+
+// escape carries out the sequence ESC b.
+func (t *Terminal) escape(b byte) {
+	switch b {
+	case '[':
+		t.state = stateCSI
+		t.params = t.params[:0]
+		t.private = 0
+	case ']':
+		t.state = stateOSC
+	case '(', ')', '*', '+':
+		t.state = stateCharset
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.lineFeed()
+		t.col = 0
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.Reset()
+	}
+}
+
+// param returns CSI parameter i, or def if it is missing or zero.
+func (t *Terminal) param(i, def int) int {
+	if i < len(t.params) && t.params[i] != 0 {
+		return t.params[i]
+	}
+	return def
+}
+
+// csi carries out the control sequence ending in final.
+func (t *Terminal) csi(final byte) {
+	if t.private != 0 {
+		// Private modes (cursor visibility, alternate screen and so on) have no
+		// meaning here.
+		return
+	}
+
+	n := t.param(0, 1)
+	switch final {
+	case 'A':
+		t.moveTo(t.col, t.row-n)
+	case 'B', 'e':
+		t.moveTo(t.col, t.row+n)
+	case 'C', 'a':
+		t.moveTo(t.col+n, t.row)
+	case 'D':
+		t.moveTo(t.col-n, t.row)
+	case 'E':
+		t.moveTo(0, t.row+n)
+	case 'F':
+		t.moveTo(0, t.row-n)
+	case 'G', '`':
+		t.moveTo(n-1, t.row)
+	case 'd':
+		t.moveTo(t.col, n-1)
+	case 'H', 'f':
+		t.moveTo(t.param(1, 1)-1, n-1)
+
+	case 'J':
+		switch t.param(0, 0) {
+		case 0:
+			t.erase(t.col, t.row, t.cols, t.row+1)
+			t.erase(0, t.row+1, t.cols, t.rows)
+		case 1:
+			t.erase(0, 0, t.cols, t.row)
+			t.erase(0, t.row, t.col+1, t.row+1)
+		case 2, 3:
+			t.erase(0, 0, t.cols, t.rows)
+		}
+	case 'K':
+		switch t.param(0, 0) {
+		case 0:
+			t.erase(t.col, t.row, t.cols, t.row+1)
+		case 1:
+			t.erase(0, t.row, t.col+1, t.row+1)
+		case 2:
+			t.erase(0, t.row, t.cols, t.row+1)
+		}
+	case 'X':
+		t.erase(t.col, t.row, min(t.col+n, t.cols), t.row+1)
+
+	case 'L':
+		if t.row >= t.top && t.row < t.bottom {
+			t.scrollDown(t.row, t.bottom, n)
+		}
+	case 'M':
+		if t.row >= t.top && t.row < t.bottom {
+			t.scrollUp(t.row, t.bottom, n)
+		}
+	case 'S':
+		t.scrollUp(t.top, t.bottom, n)
+	case 'T':
+		t.scrollDown(t.top, t.bottom, n)
+
+	case 'r':
+		top, bottom := t.param(0, 1)-1, min(t.param(1, t.rows), t.rows)
+		if bottom-top >= 2 {
+			t.top, t.bottom = top, bottom
+			t.moveTo(0, 0)
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	case 'h', 'l':
+		if t.param(0, 0) == 20 {
+			t.CRLF = final == 'h'
+		}
+
+	case 'm':
+		t.sgr()
+	}
+}
+
+func (t *Terminal) saveCursor() {
+	t.savedCol, t.savedRow, t.savedAttr = t.col, t.row, t.attr
+}
+
+func (t *Terminal) restoreCursor() {
+	t.attr = t.savedAttr
+	t.moveTo(t.savedCol, t.savedRow)
+}
+
+// sgr carries out Select Graphic Rendition, setting the attributes text is
+// printed with.
+func (t *Terminal) sgr() {
+	if len(t.params) == 0 {
+		t.attr = defaultAttrs
+		return
+	}
+
+	for i := 0; i < len(t.params); i++ {
+		switch p := t.params[i]; {
+		case p == 0:
+			t.attr = defaultAttrs
+		case p == 1:
+			t.attr.bold = true
+		case p == 4:
+			t.attr.underline = true
+		case p == 7:
+			t.attr.reverse = true
+		case p == 22:
+			t.attr.bold = false
+		case p == 24:
+			t.attr.underline = false
+		case p == 27:
+			t.attr.reverse = false
+		case p >= 30 && p <= 37:
+			t.attr.fg, t.attr.fgIndex = t.paletteColor(p-30), p-30
+		case p == 38:
+			c, used := t.extendedColor(t.params[i+1:])
+			i += used
+			if c != nil {
+				t.attr.fg, t.attr.fgIndex = c, -1
+			}
+		case p == 39:
+			t.attr.fg, t.attr.fgIndex = nil, -1
+		case p >= 40 && p <= 47:
+			t.attr.bg = t.paletteColor(p - 40)
+		case p == 48:
+			c, used := t.extendedColor(t.params[i+1:])
+			i += used
+			if c != nil {
+				t.attr.bg = c
+			}
+		case p == 49:
+			t.attr.bg = nil
+		case p >= 90 && p <= 97:
+			t.attr.fg, t.attr.fgIndex = t.paletteColor(p-90+8), -1
+		case p >= 100 && p <= 107:
+			t.attr.bg = t.paletteColor(p - 100 + 8)
+		}
+	}
+}
+
+// paletteColor returns color i of the Palette, or of XtermPalette if the Palette
+// is too short. It returns nil if neither has it.
+func (t *Terminal) paletteColor(i int) color.Color {
+	switch {
+	case i >= 0 && i < len(t.Palette):
+		return t.Palette[i]
+	case i >= 0 && i < len(XtermPalette):
+		return XtermPalette[i]
+	}
+	return nil
+}
+
+// extendedColor parses the parameters following a 38 or 48: either 5 and a
+// palette index, or 2 and red, green and blue. It returns the color, or nil if
+// the parameters are bad, and how many parameters it used.
+func (t *Terminal) extendedColor(params []int) (color.Color, int) {
+	if len(params) == 0 {
+		return nil, 0
+	}
+	switch params[0] {
+	case 5:
+		if len(params) < 2 {
+			return nil, len(params)
+		}
+		return t.paletteColor(params[1]), 2
+	case 2:
+		if len(params) < 4 {
+			return nil, len(params)
+		}
+		return color.RGBA{uint8(params[1]), uint8(params[2]), uint8(params[3]), 0xFF}, 4
+	}
+	return nil, 1
+}
@@ -0,0 +1,249 @@
+package term
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/sparques/gfx"
+)
+
+// Font draws glyphs into terminal cells.
+type Font interface {
+	// DrawCell draws r into cell of dst, in fg on a background of bg. The whole
+	// cell is drawn over, so it need not be cleared first.
+	DrawCell(dst gfx.Drawer, cell image.Rectangle, r rune, fg, bg color.Color)
+}
+
+// Screen is what a Terminal draws on. gfx.SoftScreenOf is one.
+type Screen interface {
+	gfx.Drawer
+	// CellAt returns the cell at column c and row r. A Terminal only uses it to
+	// find the size of its cells.
+	CellAt(c, r int) draw.Image
+}
+
+// Terminal is a VT100/ANSI terminal emulator drawing on a Screen. The terminal
+// covers as many whole cells as fit in the Screen's bounds.
+//
+// Scrolling uses gfx.Scroll, so a screen that is a gfx.Scroller, such as
+// gfx.SoftScreenOf, scrolls the whole terminal by panning rather than moving
+// pixels.
+type Terminal struct {
+	Screen Screen
+	Font   Font
+	// Palette holds the colors selected by index. The first 16 are the standard
+	// and bright colors. Indexes it is too short for use XtermPalette's colors.
+	Palette color.Palette
+	// Foreground and Background are the default colors.
+	Foreground, Background color.Color
+	// CRLF makes a line feed return the cursor to the first column too, as output
+	// meant for a tty expects. New sets it; ESC[20h and ESC[20l also change it.
+	CRLF bool
+
+	cell       image.Point
+	cols, rows int
+
+	col, row int
+	// wrapNext is set once a glyph is printed in the last column; the line
+	// wraps when the next one is printed.
+	wrapNext bool
+	// top and bottom are the scroll region's rows; bottom is exclusive.
+	top, bottom int
+
+	attr               attrs
+	savedAttr          attrs
+	savedCol, savedRow int
+
+	state   parseState
+	params  []int
+	private byte
+	// pending holds the start of a UTF-8 sequence split between writes.
+	pending []byte
+}
+
+// attrs are the SGR attributes text is printed with.
+type attrs struct {
+	// fg and bg are nil for the default colors.
+	fg, bg color.Color
+	// fgIndex is the palette index of fg, if it is one of the 8 standard colors
+	// (which bold brightens), otherwise -1.
+	fgIndex                  int
+	bold, underline, reverse bool
+}
+
+var defaultAttrs = attrs{fgIndex: -1}
+
+// New returns a Terminal drawing on screen with font, and clears the screen. The
+// terminal is always at least one cell, even if the screen is smaller.
+func New(screen Screen, font Font) *Terminal {
+	t := &Terminal{
+		Screen:     screen,
+		Font:       font,
+		Palette:    slices.Clone(XtermPalette),
+		Foreground: XtermPalette[7],
+		Background: XtermPalette[0],
+		CRLF:       true,
+		cell:       screen.CellAt(0, 0).Bounds().Size(),
+	}
+	t.cell.X, t.cell.Y = max(t.cell.X, 1), max(t.cell.Y, 1)
+	t.cols = max(screen.Bounds().Dx()/t.cell.X, 1)
+	t.rows = max(screen.Bounds().Dy()/t.cell.Y, 1)
+	t.Reset()
+	return t
+}
+
+// Size returns the number of columns and rows.
+func (t *Terminal) Size() (cols, rows int) {
+	return t.cols, t.rows
+}
+
+// Cursor returns the cursor's column and row, counting from zero.
+func (t *Terminal) Cursor() (col, row int) {
+	return t.col, t.row
+}
+
+// Reset puts the terminal back in its initial state and clears the screen.
+func (t *Terminal) Reset() {
+	t.attr = defaultAttrs
+	t.savedAttr = defaultAttrs
+	t.col, t.row, t.savedCol, t.savedRow = 0, 0, 0, 0
+	t.wrapNext = false
+	t.top, t.bottom = 0, t.rows
+	t.state = stateGround
+	t.pending = nil
+	t.erase(0, 0, t.cols, t.rows)
+}
+
+// Write implements io.Writer. It never fails.
+func (t *Terminal) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(t.pending) > 0 {
+		p = append(t.pending, p...)
+		t.pending = nil
+	}
+
+	for len(p) > 0 {
+		if p[0] < utf8.RuneSelf || t.state != stateGround {
+			t.parse(p[0])
+			p = p[1:]
+			continue
+		}
+		if !utf8.FullRune(p) {
+			t.pending = append([]byte(nil), p...)
+			break
+		}
+		r, size := utf8.DecodeRune(p)
+		t.print(r)
+		p = p[size:]
+	}
+
+	return n, nil
+}
+
+// cellRect returns the bounds of the cells from col0, row0 up to col1, row1.
+func (t *Terminal) cellRect(col0, row0, col1, row1 int) image.Rectangle {
+	min := t.Screen.Bounds().Min
+	return image.Rect(col0*t.cell.X, row0*t.cell.Y, col1*t.cell.X, row1*t.cell.Y).Add(min)
+}
+
+// colors returns the foreground and background to print with.
+func (t *Terminal) colors() (fg, bg color.Color) {
+	fg, bg = t.attr.fg, t.attr.bg
+	if fg == nil {
+		fg = t.Foreground
+	}
+	if t.attr.bold && t.attr.fgIndex >= 0 && t.attr.fgIndex < 8 {
+		fg = t.paletteColor(t.attr.fgIndex + 8)
+	}
+	if bg == nil {
+		bg = t.Background
+	}
+	if t.attr.reverse {
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// print draws r at the cursor and advances it.
+func (t *Terminal) print(r rune) {
+	if t.wrapNext {
+		t.col = 0
+		t.lineFeed()
+	}
+
+	fg, bg := t.colors()
+	cell := t.cellRect(t.col, t.row, t.col+1, t.row+1)
+	t.Font.DrawCell(t.Screen, cell, r, fg, bg)
+	if t.attr.underline {
+		gfx.Fill(t.Screen, image.Rect(cell.Min.X, cell.Max.Y-1, cell.Max.X, cell.Max.Y), fg)
+	}
+
+	if t.col == t.cols-1 {
+		t.wrapNext = true
+	} else {
+		t.col++
+	}
+}
+
+// erase clears the cells from col0, row0 up to col1, row1 to the current background.
+func (t *Terminal) erase(col0, row0, col1, row1 int) {
+	bg := t.attr.bg
+	if bg == nil {
+		bg = t.Background
+	}
+	gfx.Fill(t.Screen, t.cellRect(col0, row0, col1, row1), bg)
+}
+
+// moveTo moves the cursor to col, row, keeping it on the screen.
+func (t *Terminal) moveTo(col, row int) {
+	t.col = min(max(col, 0), t.cols-1)
+	t.row = min(max(row, 0), t.rows-1)
+	t.wrapNext = false
+}
+
+// lineFeed moves the cursor down a row, scrolling if it is at the bottom of the
+// scroll region.
+func (t *Terminal) lineFeed() {
+	t.wrapNext = false
+	switch {
+	case t.row == t.bottom-1:
+		t.scrollUp(t.top, t.bottom, 1)
+	case t.row < t.rows-1:
+		t.row++
+	}
+}
+
+// reverseIndex moves the cursor up a row, scrolling if it is at the top of the
+// scroll region.
+func (t *Terminal) reverseIndex() {
+	t.wrapNext = false
+	switch {
+	case t.row == t.top:
+		t.scrollDown(t.top, t.bottom, 1)
+	case t.row > 0:
+		t.row--
+	}
+}
+
+// scrollUp moves rows [from, to) up by n rows, clearing those left at the bottom.
+func (t *Terminal) scrollUp(from, to, n int) {
+	n = min(n, to-from)
+	if n <= 0 {
+		return
+	}
+	gfx.Scroll(t.Screen, t.cellRect(0, from, t.cols, to), n*t.cell.Y)
+	t.erase(0, to-n, t.cols, to)
+}
+
+// scrollDown moves rows [from, to) down by n rows, clearing those left at the top.
+func (t *Terminal) scrollDown(from, to, n int) {
+	n = min(n, to-from)
+	if n <= 0 {
+		return
+	}
+	gfx.Scroll(t.Screen, t.cellRect(0, from, t.cols, to), -n*t.cell.Y)
+	t.erase(0, from, t.cols, from+n)
+}
@@ -0,0 +1,156 @@
+package term
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/sparques/gfx"
+)
+
+// testFont fills each cell with bg, except its top left pixel which holds the
+// rune (marked by an alpha of 0xFE) and the pixel right of that, which holds fg.
+type testFont struct{}
+
+func (testFont) DrawCell(dst gfx.Drawer, cell image.Rectangle, r rune, fg, bg color.Color) {
+	gfx.Fill(dst, cell, bg)
+	dst.Set(cell.Min.X, cell.Min.Y, color.RGBA{uint8(r), uint8(r >> 8), uint8(r >> 16), 0xFE})
+	dst.Set(cell.Min.X+1, cell.Min.Y, fg)
+}
+
+// newTestTerminal returns a 10x5 terminal.
+func newTestTerminal() (*Terminal, *gfx.SoftScreen) {
+	screen := gfx.NewSoftScreen(image.Rect(0, 0, 2, 2), image.Rect(0, 0, 20, 10), image.Rect(0, 0, 20, 10))
+	return New(screen, testFont{}), screen
+}
+
+// text returns what is on screen, with blank cells as '.'.
+func text(t *Terminal) string {
+	var sb strings.Builder
+	for row := 0; row < t.rows; row++ {
+		for col := 0; col < t.cols; col++ {
+			min := t.cellRect(col, row, col+1, row+1).Min
+			c := color.RGBAModel.Convert(t.Screen.At(min.X, min.Y)).(color.RGBA)
+			if c.A != 0xFE {
+				sb.WriteByte('.')
+			} else {
+				sb.WriteRune(rune(c.R) | rune(c.G)<<8 | rune(c.B)<<16)
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// cellColors returns the fg and bg of the cell at col, row.
+func cellColors(t *Terminal, col, row int) (fg, bg color.Color) {
+	min := t.cellRect(col, row, col+1, row+1).Min
+	return t.Screen.At(min.X+1, min.Y), t.Screen.At(min.X, min.Y+1)
+}
+
+func Test_Terminal(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"lines", "hello\nworld", "hello.....\nworld.....\n..........\n..........\n..........\n"},
+		{"wrap", "0123456789abc", "0123456789\nabc.......\n..........\n..........\n..........\n"},
+		{"exact width", "0123456789\nx", "0123456789\nx.........\n..........\n..........\n..........\n"},
+		{"scroll", "1\n2\n3\n4\n5\n6\n7", "3.........\n4.........\n5.........\n6.........\n7.........\n"},
+		{"cursor", "\x1b[3;4Hx\x1b[Ay\x1b[2Dz\x1b[10;10Hw", "..........\n...zy.....\n...x......\n..........\n.........w\n"},
+		{"erase line", "abcdef\x1b[3D\x1b[K\rX", "Xbc.......\n..........\n..........\n..........\n..........\n"},
+		{"erase display", "a\nb\nc\x1b[2;1H\x1b[J", "a.........\n..........\n..........\n..........\n..........\n"},
+		{"erase all", "a\nb\x1b[2Jc", "..........\n.c........\n..........\n..........\n..........\n"},
+		{"tab", "a\tb", "a.......b.\n..........\n..........\n..........\n..........\n"},
+		{"utf8", "héllo →", "héllo →...\n..........\n..........\n..........\n..........\n"},
+		{"scroll region", "top\x1b[2;4r\x1b[4;1H1\n2\n3\x1b[r\x1b[5;1Hend", "top.......\n1.........\n2.........\n3.........\nend.......\n"},
+		{"insert line", "a\nb\nc\x1b[2;1H\x1b[L", "a.........\n..........\nb.........\nc.........\n..........\n"},
+		{"delete line", "a\nb\nc\x1b[1;1H\x1b[M", "b.........\nc.........\n..........\n..........\n..........\n"},
+		{"reverse index", "a\nb\x1b[1;1H\x1bMz", "z.........\na.........\nb.........\n..........\n..........\n"},
+		{"save restore", "ab\x1b7\x1b[4;4Hx\x1b8c", "abc.......\n..........\n..........\n...x......\n..........\n"},
+		{"osc ignored", "\x1b]0;title\x07a\x1b]2;t\x1b\\b", "ab........\n..........\n..........\n..........\n..........\n"},
+	}
+
+	for _, test := range tests {
+		term, _ := newTestTerminal()
+		fmt.Fprint(term, test.in)
+		if got := text(term); got != test.want {
+			t.Errorf("%s: got\n%swant\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+// Test_TerminalSplitWrites feeds input a byte at a time, splitting escape
+// sequences and UTF-8 between writes.
+func Test_TerminalSplitWrites(t *testing.T) {
+	in := "\x1b[2;3H→\x1b[31mé"
+	whole, _ := newTestTerminal()
+	split, _ := newTestTerminal()
+	whole.Write([]byte(in))
+	for i := 0; i < len(in); i++ {
+		split.Write([]byte{in[i]})
+	}
+	if text(whole) != text(split) {
+		t.Errorf("got\n%swant\n%s", text(split), text(whole))
+	}
+}
+
+func Test_TerminalSGR(t *testing.T) {
+	term, _ := newTestTerminal()
+	fmt.Fprint(term, "a\x1b[31mb\x1b[1mc\x1b[0;38;2;1;2;3;48;5;196md\x1b[7me\x1b[m")
+
+	check := func(col int, wantFg, wantBg color.Color) {
+		t.Helper()
+		fg, bg := cellColors(term, col, 0)
+		if fg != wantFg || bg != wantBg {
+			t.Errorf("column %d: got %v on %v, want %v on %v", col, fg, bg, wantFg, wantBg)
+		}
+	}
+	check(0, XtermPalette[7], XtermPalette[0])
+	check(1, XtermPalette[1], XtermPalette[0])
+	check(2, XtermPalette[9], XtermPalette[0])
+	check(3, color.RGBA{1, 2, 3, 0xFF}, XtermPalette[196])
+	check(4, XtermPalette[196], color.RGBA{1, 2, 3, 0xFF})
+
+	if term.attr != defaultAttrs {
+		t.Error("SGR with no parameters did not reset")
+	}
+}
+
+func Test_TerminalPalette(t *testing.T) {
+	// each terminal has its own copy of the palette
+	a, _ := newTestTerminal()
+	b, _ := newTestTerminal()
+	was := XtermPalette[1]
+	a.Palette[1] = color.RGBA{1, 2, 3, 0xFF}
+	if b.Palette[1] != was || XtermPalette[1] != was {
+		t.Error("changing one terminal's palette changed others")
+	}
+
+	// a short palette falls back to the default one
+	red := color.RGBA{0xC0, 0, 0, 0xFF}
+	a.Palette = color.Palette{color.Black, red}
+	fmt.Fprint(a, "\x1b[31ma\x1b[1mb\x1b[0;92;105mc\x1b[38;5;200md")
+	for col, want := range []color.Color{red, XtermPalette[9], XtermPalette[10], XtermPalette[200]} {
+		if fg, _ := cellColors(a, col, 0); fg != want {
+			t.Errorf("column %d: got %v, want %v", col, fg, want)
+		}
+	}
+	if _, bg := cellColors(a, 2, 0); bg != XtermPalette[13] {
+		t.Errorf("bright background %v, want %v", bg, XtermPalette[13])
+	}
+}
+
+func Test_TerminalTinyScreen(t *testing.T) {
+	// a screen smaller than a cell still holds one
+	screen := gfx.NewSoftScreen(image.Rect(0, 0, 8, 8), image.Rect(0, 0, 4, 4), image.Rect(0, 0, 4, 4))
+	term := New(screen, testFont{})
+	if cols, rows := term.Size(); cols != 1 || rows != 1 {
+		t.Fatalf("size %dx%d, want 1x1", cols, rows)
+	}
+	fmt.Fprint(term, "ab\ncd\x1b[5D\x1b[5A")
+	if col, row := term.Cursor(); col != 0 || row != 0 {
+		t.Errorf("cursor at %d,%d", col, row)
+	}
+}
@@ -0,0 +1,218 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// TileBuffer tracks damage on a fixed grid of tiles rather than as free-form
+// rectangles. It sits between drawing code and a Blitter, like DoubleBuffer,
+// and on Flush blits only the tiles that changed.
+//
+// This suits slow serial displays, where a flush costs roughly the number of
+// bytes sent: a tile grid never degrades to one big bounding box, and each
+// tile lines up with the display's address window.
+//
+// Only changes made through the TileBuffer are tracked. If the back buffer
+// changes behind its back (for example, SoftScreenOf.Pan), call Invalidate.
+type TileBuffer struct {
+	// Drawer is the back buffer.
+	Drawer
+	// Front is where Flush sends changed tiles.
+	Front Blitter
+	// MergeRuns makes Flush send horizontally adjacent dirty tiles as one blit.
+	MergeRuns bool
+	// Hash makes Flush skip dirty tiles whose contents hash the same as when
+	// they were last flushed, such as text redrawn unchanged. It costs reading
+	// every pixel of each dirty tile.
+	Hash bool
+	// ScrollFill is applied after scrolling, on top of whatever the back buffer does.
+	ScrollFill ScrollFill
+
+	tileSize   image.Point
+	cols, rows int
+	tiles      []tileState
+}
+
+type tileState struct {
+	dirty  bool
+	hashed bool
+	hash   uint64
+}
+
+// NewTileBuffer returns a TileBuffer drawing into back and flushing to front,
+// tracking damage in tiles of tileSize. Tiles on the right and bottom edges are
+// cut short if the bounds are not a multiple of tileSize.
+func NewTileBuffer(back Drawer, front Blitter, tileSize image.Point) *TileBuffer {
+	if tileSize.X <= 0 || tileSize.Y <= 0 {
+		panic("gfx: tile size must be positive")
+	}
+	b := back.Bounds()
+	cols := (b.Dx() + tileSize.X - 1) / tileSize.X
+	rows := (b.Dy() + tileSize.Y - 1) / tileSize.Y
+	return &TileBuffer{
+		Drawer:   back,
+		Front:    front,
+		tileSize: tileSize,
+		cols:     cols,
+		rows:     rows,
+		tiles:    make([]tileState, cols*rows),
+	}
+}
+
+// TileSize returns the size of the tiles tb tracks.
+func (tb *TileBuffer) TileSize() image.Point {
+	return tb.tileSize
+}
+
+// tileRect returns the bounds of the tile at col, row.
+func (tb *TileBuffer) tileRect(col, row int) image.Rectangle {
+	min := tb.Bounds().Min.Add(image.Pt(col*tb.tileSize.X, row*tb.tileSize.Y))
+	return image.Rectangle{min, min.Add(tb.tileSize)}.Intersect(tb.Bounds())
+}
+
+func (tb *TileBuffer) Set(x, y int, c color.Color) {
+	tb.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	tb.Drawer.Set(x, y, c)
+}
+
+// Fill implements gfx.Filler.
+func (tb *TileBuffer) Fill(r image.Rectangle, c color.Color) {
+	tb.dirtyAdd(r)
+	Fill(tb.Drawer, r, c)
+}
+
+// Blit implements gfx.Blitter.
+func (tb *TileBuffer) Blit(src image.Image, at image.Point) {
+	tb.dirtyAdd(src.Bounds().Sub(src.Bounds().Min).Add(at))
+	Blit(tb.Drawer, src, at)
+}
+
+// Scroll implements gfx.Scroller.
+func (tb *TileBuffer) Scroll(amount int) {
+	tb.RegionScroll(tb.Bounds(), amount)
+}
+
+// RegionScroll implements gfx.RegionScroller.
+func (tb *TileBuffer) RegionScroll(region image.Rectangle, amount int) {
+	region = region.Intersect(tb.Bounds())
+	if region.Empty() || amount == 0 {
+		return
+	}
+	tb.dirtyAdd(region)
+	Scroll(tb.Drawer, region, amount)
+	tb.ScrollFill.apply(tb, region, image.Pt(0, amount))
+}
+
+// VectorScroll implements gfx.VectorScroller. If the back buffer is not a
+// VectorScroller, the software VectorScroll is used.
+func (tb *TileBuffer) VectorScroll(region image.Rectangle, vector image.Point) {
+	region = region.Intersect(tb.Bounds())
+	if region.Empty() || vector == (image.Point{}) {
+		return
+	}
+	tb.dirtyAdd(region)
+	if vs, ok := tb.Drawer.(VectorScroller); ok {
+		vs.VectorScroll(region, vector)
+	} else {
+		VectorScroll(tb.Drawer, region, vector)
+	}
+	tb.ScrollFill.apply(tb, region, vector)
+}
+
+// Invalidate marks every tile touching r as dirty.
+func (tb *TileBuffer) Invalidate(r image.Rectangle) {
+	tb.dirtyAdd(r)
+}
+
+// DirtyRects returns the rectangles Flush would blit, ignoring Hash.
+func (tb *TileBuffer) DirtyRects() []image.Rectangle {
+	var rects []image.Rectangle
+	tb.runs(func(r image.Rectangle, _, _ int) {
+		rects = append(rects, r)
+	})
+	return rects
+}
+
+// Flush implements gfx.DoubleBufferer. Dirty tiles are blitted to the front,
+// which is then flushed too if it is a DoubleBufferer.
+func (tb *TileBuffer) Flush() {
+	if tb.Hash {
+		// Drop the tiles that haven't really changed before looking for runs.
+		for i := range tb.tiles {
+			t := &tb.tiles[i]
+			if !t.dirty {
+				continue
+			}
+			h := hashPixels(tb.Drawer, tb.tileRect(i%tb.cols, i/tb.cols))
+			if t.hashed && t.hash == h {
+				t.dirty = false
+			}
+			t.hash, t.hashed = h, true
+		}
+	}
+
+	tb.runs(func(r image.Rectangle, first, last int) {
+		if sub, ok := subImage(tb.Drawer, r); ok {
+			tb.Front.Blit(sub, r.Min)
+		} else {
+			tb.Front.Blit(window{tb.Drawer, r}, r.Min)
+		}
+		for i := first; i <= last; i++ {
+			tb.tiles[i].dirty = false
+			if !tb.Hash {
+				// the front no longer holds what the tile last hashed as
+				tb.tiles[i].hashed = false
+			}
+		}
+	})
+
+	if front, ok := tb.Front.(DoubleBufferer); ok {
+		front.Flush()
+	}
+}
+
+// runs calls fn with the bounds of each dirty tile, or of each horizontal run of
+// them if MergeRuns is set, along with the indices of the first and last tiles.
+func (tb *TileBuffer) runs(fn func(r image.Rectangle, first, last int)) {
+	for row := 0; row < tb.rows; row++ {
+		for col := 0; col < tb.cols; col++ {
+			i := row*tb.cols + col
+			if !tb.tiles[i].dirty {
+				continue
+			}
+			end := col
+			for tb.MergeRuns && end+1 < tb.cols && tb.tiles[i+end+1-col].dirty {
+				end++
+			}
+			fn(tb.tileRect(col, row).Union(tb.tileRect(end, row)), i, i+end-col)
+			col = end
+		}
+	}
+}
+
+func (tb *TileBuffer) dirtyAdd(rect image.Rectangle) {
+	rect = rect.Intersect(tb.Bounds()).Sub(tb.Bounds().Min)
+	if rect.Empty() {
+		return
+	}
+	for row := rect.Min.Y / tb.tileSize.Y; row <= (rect.Max.Y-1)/tb.tileSize.Y; row++ {
+		for col := rect.Min.X / tb.tileSize.X; col <= (rect.Max.X-1)/tb.tileSize.X; col++ {
+			tb.tiles[row*tb.cols+col].dirty = true
+		}
+	}
+}
+
+// hashPixels returns an FNV-1a hash of the colors within r.
+func hashPixels(img image.Image, r image.Rectangle) uint64 {
+	const prime = 1099511628211
+	var h uint64 = 14695981039346656037
+	forAllPix(r, func(x, y int) {
+		cr, cg, cb, ca := img.At(x, y).RGBA()
+		for _, v := range [...]uint32{cr, cg, cb, ca} {
+			h = (h ^ uint64(v&0xFF)) * prime
+			h = (h ^ uint64(v>>8)) * prime
+		}
+	})
+	return h
+}
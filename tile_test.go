@@ -0,0 +1,107 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// interface checks
+var (
+	_ DoubleBufferer = &TileBuffer{}
+	_ Blitter        = &TileBuffer{}
+	_ Filler         = &TileBuffer{}
+	_ VectorScroller = &TileBuffer{}
+)
+
+func Test_TileBuffer(t *testing.T) {
+	bounds := image.Rect(0, 0, 40, 30)
+	front := &recordingBlitter{RGB565: NewRGB565(bounds)}
+	tb := NewTileBuffer(NewRGB565(bounds), front, image.Pt(16, 16))
+
+	// one pixel dirties one tile; the edge tile is cut short
+	tb.Set(35, 20, color.White)
+	if got := tb.DirtyRects(); len(got) != 1 || got[0] != image.Rect(32, 16, 40, 30) {
+		t.Errorf("one pixel dirtied %v", got)
+	}
+	tb.Flush()
+	if len(front.blits) != 1 || len(tb.DirtyRects()) != 0 {
+		t.Errorf("Flush blitted %v, left %v dirty", front.blits, tb.DirtyRects())
+	}
+
+	// a row across all tiles is three blits, or one when merging runs
+	tb.Fill(image.Rect(0, 2, 40, 3), color.White)
+	if got := tb.DirtyRects(); len(got) != 3 {
+		t.Errorf("row dirtied %v", got)
+	}
+	tb.MergeRuns = true
+	if got := tb.DirtyRects(); len(got) != 1 || got[0] != image.Rect(0, 0, 40, 16) {
+		t.Errorf("merged row dirtied %v", got)
+	}
+
+	// redrawing the same thing is skipped when hashing
+	tb.Hash = true
+	tb.Flush()
+	front.blits = nil
+	tb.Fill(image.Rect(0, 2, 40, 3), color.White)
+	tb.Set(1, 20, color.White)
+	tb.Flush()
+	if len(front.blits) != 1 || front.blits[0] != image.Rect(0, 16, 16, 30) {
+		t.Errorf("with Hash, flushed %v", front.blits)
+	}
+
+	// a tile flushed while not hashing no longer matches its old hash
+	tb.Hash = false
+	tb.Set(1, 20, color.Black)
+	tb.Flush()
+	tb.Hash = true
+	front.blits = nil
+	tb.Set(1, 20, color.White)
+	tb.Flush()
+	if len(front.blits) != 1 || front.blits[0] != image.Rect(0, 16, 16, 30) {
+		t.Errorf("after turning Hash back on, flushed %v", front.blits)
+	}
+}
+
+// Test_TileBufferRandom checks the front always matches the back after Flush,
+// for a few kinds of back buffer.
+func Test_TileBufferRandom(t *testing.T) {
+	rand.Seed(12)
+	bounds := image.Rect(0, 0, 32, 24)
+	backs := map[string]func() Drawer{
+		"RGBA":       func() Drawer { return NewRGBA(image.NewRGBA(bounds)) },
+		"SoftScreen": func() Drawer { return NewSoftScreen(image.Rect(0, 0, 8, 8), bounds, bounds) },
+		"Mono":       func() Drawer { return NewMono(bounds, MonoVertical) },
+	}
+
+	for name, newBack := range backs {
+		back := newBack()
+		front := &recordingBlitter{RGB565: NewRGB565(bounds)}
+		tb := NewTileBuffer(back, front, image.Pt(1+rand.Intn(12), 1+rand.Intn(12)))
+		tb.Invalidate(bounds)
+		tb.MergeRuns = rand.Intn(2) == 0
+
+		for i := 0; i < 100; i++ {
+			c := color.RGBA{uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256)), 0xFF}
+			switch rand.Intn(3) {
+			case 0:
+				tb.Set(rand.Intn(bounds.Dx()), rand.Intn(bounds.Dy()), c)
+			case 1:
+				tb.Fill(randomRect(bounds), c)
+			case 2:
+				tb.VectorScroll(randomRect(bounds), image.Pt(rand.Intn(9)-4, rand.Intn(9)-4))
+			}
+
+			if rand.Intn(5) == 0 {
+				tb.Hash = rand.Intn(2) == 0
+				tb.Flush()
+				forAllPix(bounds, func(x, y int) {
+					if front.At(x, y) != front.ColorModel().Convert(back.At(x, y)) {
+						t.Fatalf("%s: iteration %d: front and back differ at (%d,%d)", name, i, x, y)
+					}
+				})
+			}
+		}
+	}
+}
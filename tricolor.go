@@ -0,0 +1,201 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// Ink is one of the three colors of a black/white/red e-paper panel.
+type Ink uint8
+
+const (
+	InkWhite Ink = iota
+	InkBlack
+	InkRed
+)
+
+// InkModel maps colors to the nearest Ink.
+var InkModel = color.ModelFunc(inkModelFunc)
+
+func inkModelFunc(c color.Color) color.Color {
+	if ink, ok := c.(Ink); ok {
+		return ink
+	}
+
+	r, g, b, _ := c.RGBA()
+	r, g, b = r>>8, g>>8, b>>8
+
+	// squared distances to white, black and red
+	dw := sq(0xFF-r) + sq(0xFF-g) + sq(0xFF-b)
+	dk := sq(r) + sq(g) + sq(b)
+	dr := sq(0xFF-r) + sq(g) + sq(b)
+
+	switch {
+	case dr < dw && dr < dk:
+		return InkRed
+	case dk < dw:
+		return InkBlack
+	}
+	return InkWhite
+}
+
+func sq(v uint32) uint32 {
+	return v * v
+}
+
+// Convert makes Ink implement color.Model
+func (c Ink) Convert(in color.Color) color.Color {
+	return inkModelFunc(in)
+}
+
+func (c Ink) RGBA() (r, g, b, a uint32) {
+	switch c {
+	case InkBlack:
+		return 0, 0, 0, 0xFFFF
+	case InkRed:
+		return 0xFFFF, 0, 0, 0xFFFF
+	}
+	return 0xFFFF, 0xFFFF, 0xFFFF, 0xFFFF
+}
+
+// TriColor is a framebuffer for black/white/red e-paper panels. It keeps two
+// independent 1bpp planes, one for each ink, in the MonoHorizontal layout these
+// controllers use, so each can be sent as-is. A set bit means ink; a pixel with
+// neither bit set is white. Red wins over black should both be set.
+//
+// Any color can be drawn; it is mapped to the nearest Ink.
+type TriColor struct {
+	// Black is the black ink plane.
+	Black *Mono
+	// Red is the red ink plane.
+	Red *Mono
+
+	doubleBuf *TriColor
+	dirty     Region
+}
+
+// NewTriColor returns a new TriColor framebuffer with the given bounds.
+func NewTriColor(r image.Rectangle) *TriColor {
+	return &TriColor{
+		Black: NewMono(r, MonoHorizontal),
+		Red:   NewMono(r, MonoHorizontal),
+	}
+}
+
+// NewTriColorWithDoubleBuffer returns a new TriColor framebuffer that buffers all
+// changes; they are copied into base when Flush is called.
+func NewTriColorWithDoubleBuffer(base *TriColor) *TriColor {
+	buf := NewTriColor(base.Bounds())
+	buf.Blit(base, base.Bounds().Min)
+	buf.doubleBuf = base
+	return buf
+}
+
+func (t *TriColor) ColorModel() color.Model { return InkModel }
+
+func (t *TriColor) Bounds() image.Rectangle { return t.Black.Rect }
+
+func (t *TriColor) At(x, y int) color.Color {
+	return t.InkAt(x, y)
+}
+
+// InkAt returns the ink at (x, y).
+func (t *TriColor) InkAt(x, y int) Ink {
+	switch {
+	case t.Red.BitAt(x, y):
+		return InkRed
+	case t.Black.BitAt(x, y):
+		return InkBlack
+	}
+	return InkWhite
+}
+
+func (t *TriColor) Set(x, y int, c color.Color) {
+	t.SetInk(x, y, inkModelFunc(c).(Ink))
+}
+
+// SetInk sets the pixel at (x, y) without going through the color.Color interface.
+func (t *TriColor) SetInk(x, y int, ink Ink) {
+	if !(image.Point{x, y}.In(t.Bounds())) {
+		return
+	}
+	t.dirtyAdd(image.Rect(x, y, x+1, y+1))
+	t.Black.setBit(x, y, ink == InkBlack)
+	t.Red.setBit(x, y, ink == InkRed)
+}
+
+// Fill implements gfx.Filler. Whole bytes of each plane are written wherever
+// where covers them.
+func (t *TriColor) Fill(where image.Rectangle, c color.Color) {
+	where = t.Bounds().Intersect(where)
+	if where.Empty() {
+		return
+	}
+	t.dirtyAdd(where)
+
+	ink := inkModelFunc(c).(Ink)
+	t.Black.fill(where, ink == InkBlack)
+	t.Red.fill(where, ink == InkRed)
+}
+
+// Blit implements gfx.Blitter. Blitting from another *TriColor copies each plane
+// separately, a byte at a time when they are aligned.
+func (t *TriColor) Blit(src image.Image, where image.Point) {
+	destRect, sp := clipBlit(t.Bounds(), src.Bounds(), where)
+	if destRect.Empty() {
+		return
+	}
+	t.dirtyAdd(destRect)
+
+	if s, ok := src.(*TriColor); ok {
+		t.Black.Blit(s.Black.SubImage(image.Rectangle{sp, sp.Add(destRect.Size())}), destRect.Min)
+		t.Red.Blit(s.Red.SubImage(image.Rectangle{sp, sp.Add(destRect.Size())}), destRect.Min)
+		return
+	}
+
+	for y := 0; y < destRect.Dy(); y++ {
+		for x := 0; x < destRect.Dx(); x++ {
+			ink := inkModelFunc(src.At(sp.X+x, sp.Y+y)).(Ink)
+			t.Black.setBit(destRect.Min.X+x, destRect.Min.Y+y, ink == InkBlack)
+			t.Red.setBit(destRect.Min.X+x, destRect.Min.Y+y, ink == InkRed)
+		}
+	}
+}
+
+// SubImage returns an image representing the portion of t visible through r. See
+// Mono.SubImage for when pixels are shared.
+func (t *TriColor) SubImage(r image.Rectangle) image.Image {
+	return &TriColor{
+		Black: t.Black.SubImage(r).(*Mono),
+		Red:   t.Red.SubImage(r).(*Mono),
+	}
+}
+
+// Flush implements gfx.DoubleBufferer. If t was not created with
+// NewTriColorWithDoubleBuffer, Flush does nothing.
+func (t *TriColor) Flush() {
+	if t.doubleBuf == nil {
+		return
+	}
+
+	for _, r := range t.dirty.Rects() {
+		// widen each dirty rectangle to whole bytes, so the planes can be copied directly
+		r.Min.X -= (r.Min.X - t.Bounds().Min.X) % 8
+		t.doubleBuf.Blit(t.SubImage(r), r.Min)
+	}
+
+	t.dirty.Clear()
+}
+
+// DirtyRects returns the non-overlapping rectangles changed since the last Flush.
+// Changes are only tracked if t has a double buffer.
+func (t *TriColor) DirtyRects() []image.Rectangle {
+	return t.dirty.Rects()
+}
+
+func (t *TriColor) dirtyAdd(rect image.Rectangle) {
+	if t.doubleBuf == nil {
+		return
+	}
+	t.dirty.Add(rect.Intersect(t.Bounds()))
+}
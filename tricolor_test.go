@@ -0,0 +1,113 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+// interface checks
+var (
+	_ Blitter        = &TriColor{}
+	_ Filler         = &TriColor{}
+	_ DoubleBufferer = &TriColor{}
+)
+
+func Test_InkModel(t *testing.T) {
+	for _, tc := range []struct {
+		c    color.Color
+		want Ink
+	}{
+		{color.White, InkWhite},
+		{color.Black, InkBlack},
+		{color.RGBA{0xFF, 0, 0, 0xFF}, InkRed},
+		{color.RGBA{0xFF, 0x80, 0, 0xFF}, InkRed},
+		{color.RGBA{0xFF, 0xC0, 0xC0, 0xFF}, InkWhite},
+		{color.RGBA{0x20, 0x20, 0x40, 0xFF}, InkBlack},
+		{color.Transparent, InkBlack},
+	} {
+		if got := InkModel.Convert(tc.c); got != tc.want {
+			t.Errorf("InkModel.Convert(%v) = %v, want %v", tc.c, got, tc.want)
+		}
+	}
+}
+
+func Test_TriColor(t *testing.T) {
+	front := NewTriColor(image.Rect(0, 0, 20, 10))
+	tc := NewTriColorWithDoubleBuffer(front)
+
+	tc.Fill(tc.Bounds(), color.White)
+	tc.Fill(image.Rect(0, 0, 10, 5), color.Black)
+	tc.Set(3, 3, color.RGBA{0xFF, 0, 0, 0xFF})
+
+	// drawing code that knows nothing about gfx works too
+	draw.Draw(tc, image.Rect(12, 0, 20, 2), image.NewUniform(color.RGBA{0xD0, 0x10, 0x10, 0xFF}), image.Point{}, draw.Src)
+
+	if front.InkAt(3, 3) != InkWhite {
+		t.Fatal("front buffer changed before Flush")
+	}
+	tc.Flush()
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			want := InkWhite
+			switch {
+			case x == 3 && y == 3, x >= 12 && y < 2:
+				want = InkRed
+			case x < 10 && y < 5:
+				want = InkBlack
+			}
+			if got := front.InkAt(x, y); got != want {
+				t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+
+	// the planes are exclusive
+	if front.Black.BitAt(3, 3) {
+		t.Error("red pixel also set in black plane")
+	}
+	if front.Black.Pix[0] != 0xFF || front.Black.Pix[1] != 0xC0 {
+		t.Errorf("black plane row 0 = % x", front.Black.Pix[:3])
+	}
+}
+
+func Test_TriColorSelfBlit(t *testing.T) {
+	rand.Seed(4)
+	bounds := image.Rect(0, 0, 24, 20)
+	for i := 0; i < 200; i++ {
+		tc := NewTriColor(bounds)
+		before := make(map[image.Point]Ink)
+		forAllPix(bounds, func(x, y int) {
+			ink := Ink(rand.Intn(3))
+			tc.SetInk(x, y, ink)
+			before[image.Pt(x, y)] = ink
+		})
+
+		// byte aligned or not, moving in any direction
+		sr := randomRect(bounds)
+		if rand.Intn(2) == 0 {
+			sr.Min.X &^= 7
+		}
+		src := tc.SubImage(sr)
+		at := image.Pt(rand.Intn(32)-4, rand.Intn(28)-4)
+		if rand.Intn(2) == 0 {
+			at.X &^= 7
+		}
+		tc.Blit(src, at)
+
+		moved := src.Bounds().Sub(src.Bounds().Min).Add(at)
+		forAllPix(bounds, func(x, y int) {
+			p := image.Pt(x, y)
+			want := before[p]
+			if p.In(moved) {
+				want = before[p.Sub(at).Add(src.Bounds().Min)]
+			}
+			if got := tc.InkAt(x, y); got != want {
+				t.Fatalf("Blit of %v to %v: %v is %v, want %v", src.Bounds(), at, p, got, want)
+			}
+		})
+	}
+}